@@ -0,0 +1,204 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BradyPlanden/prt/internal/github"
+)
+
+// lastUsedFile is touched in a mirror each time a clone borrows from it.
+const lastUsedFile = "prt-last-used"
+
+// GitClient defines the git operations required by Cache.
+type GitClient interface {
+	IsGitRepo(ctx context.Context, repoDir string) (bool, error)
+	CloneMirror(ctx context.Context, url string, dest string) error
+	FetchPrune(ctx context.Context, repoDir string, remote string) error
+	GC(ctx context.Context, repoDir string) error
+}
+
+// Cache manages mirrors under <dir>/<host>/<owner>/<repo>.git.
+type Cache struct {
+	dir string
+	git GitClient
+}
+
+// Mirror describes one cached repository.
+type Mirror struct {
+	Path string
+	// Repo is "<host>/<owner>/<repo>".
+	Repo     string
+	Size     int64
+	LastUsed time.Time
+}
+
+// New constructs a Cache rooted at dir.
+func New(dir string, client GitClient) *Cache {
+	return &Cache{dir: dir, git: client}
+}
+
+// Dir returns the cache root.
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// MirrorPath returns where repo is mirrored.
+func (c *Cache) MirrorPath(repo github.Repository) string {
+	return filepath.Join(c.dir, strings.ReplaceAll(repo.HostName(), ":", "-"), repo.Owner, repo.Name+".git")
+}
+
+// Ensure creates the mirror of repo, or brings an existing one up to date,
+// and returns its path.
+func (c *Cache) Ensure(ctx context.Context, repo github.Repository) (string, error) {
+	path := c.MirrorPath(repo)
+	if _, err := os.Stat(path); err == nil {
+		isRepo, err := c.git.IsGitRepo(ctx, path)
+		if err != nil {
+			return "", err
+		}
+		if !isRepo {
+			return "", fmt.Errorf("cached mirror is not a git repository: %s", path)
+		}
+		if err := c.git.FetchPrune(ctx, path, "origin"); err != nil {
+			return "", fmt.Errorf("update mirror %s: %w", path, err)
+		}
+	} else {
+		if err := c.create(ctx, repo.CloneURL, path); err != nil {
+			return "", err
+		}
+	}
+	if err := touch(filepath.Join(path, lastUsedFile)); err != nil {
+		return "", fmt.Errorf("record mirror use: %w", err)
+	}
+	return path, nil
+}
+
+// create clones into a scratch directory next to path and renames it into
+// place, so an interrupted clone or a concurrent prt never leaves a
+// half-written mirror behind.
+func (c *Cache) create(ctx context.Context, url string, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create cache dir: %w", err)
+	}
+	scratch := path + ".tmp-" + strconv.Itoa(os.Getpid())
+	if err := os.RemoveAll(scratch); err != nil {
+		return fmt.Errorf("remove stale mirror clone: %w", err)
+	}
+	if err := c.git.CloneMirror(ctx, url, scratch); err != nil {
+		os.RemoveAll(scratch)
+		return err
+	}
+	if err := os.Rename(scratch, path); err != nil {
+		os.RemoveAll(scratch)
+		// Another prt finished the same mirror first.
+		if _, statErr := os.Stat(path); statErr == nil {
+			return nil
+		}
+		return fmt.Errorf("move mirror into place: %w", err)
+	}
+	return nil
+}
+
+// List returns every mirror in the cache, sorted by repository, with its
+// size on disk.
+func (c *Cache) List() ([]Mirror, error) {
+	paths, err := filepath.Glob(filepath.Join(c.dir, "*", "*", "*.git"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	var mirrors []Mirror
+	for _, path := range paths {
+		if info, err := os.Stat(path); err != nil || !info.IsDir() {
+			continue
+		}
+		rel, err := filepath.Rel(c.dir, path)
+		if err != nil {
+			continue
+		}
+		mirror := Mirror{Path: path, Repo: strings.TrimSuffix(filepath.ToSlash(rel), ".git")}
+		mirror.Size, err = dirSize(path)
+		if err != nil {
+			return nil, fmt.Errorf("measure mirror %s: %w", path, err)
+		}
+		if info, err := os.Stat(filepath.Join(path, lastUsedFile)); err == nil {
+			mirror.LastUsed = info.ModTime()
+		}
+		mirrors = append(mirrors, mirror)
+	}
+	return mirrors, nil
+}
+
+// Prune removes mirrors no clone has used for olderThan; 0 removes all of
+// them. With dryRun it only reports what it would remove.
+func (c *Cache) Prune(olderThan time.Duration, dryRun bool) ([]Mirror, error) {
+	mirrors, err := c.List()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	var pruned []Mirror
+	for _, mirror := range mirrors {
+		if olderThan > 0 && !mirror.LastUsed.IsZero() && now.Sub(mirror.LastUsed) < olderThan {
+			continue
+		}
+		if !dryRun {
+			if err := os.RemoveAll(mirror.Path); err != nil {
+				return pruned, fmt.Errorf("remove mirror %s: %w", mirror.Path, err)
+			}
+		}
+		pruned = append(pruned, mirror)
+	}
+	return pruned, nil
+}
+
+// GC runs git gc in mirror and returns its new size.
+func (c *Cache) GC(ctx context.Context, mirror Mirror) (int64, error) {
+	if err := c.git.GC(ctx, mirror.Path); err != nil {
+		return 0, err
+	}
+	return dirSize(mirror.Path)
+}
+
+func dirSize(path string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(path, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			// Files can vanish while git is repacking.
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if entry.Type().IsRegular() {
+			info, err := entry.Info()
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					return nil
+				}
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+func touch(path string) error {
+	now := time.Now()
+	if err := os.Chtimes(path, now, now); err == nil {
+		return nil
+	}
+	return os.WriteFile(path, nil, 0o644)
+}
@@ -0,0 +1,147 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/BradyPlanden/prt/internal/github"
+)
+
+type fakeGit struct {
+	clones   []string
+	fetches  []string
+	gcs      []string
+	cloneErr error
+}
+
+func (f *fakeGit) IsGitRepo(_ context.Context, repoDir string) (bool, error) {
+	return pathExists(filepath.Join(repoDir, "HEAD")), nil
+}
+
+func (f *fakeGit) CloneMirror(_ context.Context, url string, dest string) error {
+	f.clones = append(f.clones, url)
+	if f.cloneErr != nil {
+		return f.cloneErr
+	}
+	if err := os.MkdirAll(filepath.Join(dest, "objects"), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dest, "HEAD"), []byte("ref: refs/heads/main\n"), 0o644)
+}
+
+func (f *fakeGit) FetchPrune(_ context.Context, repoDir string, _ string) error {
+	f.fetches = append(f.fetches, repoDir)
+	return nil
+}
+
+func (f *fakeGit) GC(_ context.Context, repoDir string) error {
+	f.gcs = append(f.gcs, repoDir)
+	return nil
+}
+
+func pathExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func testRepo(owner, name string) github.Repository {
+	return github.Repository{Owner: owner, Name: name, CloneURL: "https://github.com/" + owner + "/" + name + ".git"}
+}
+
+func TestEnsureClonesThenFetches(t *testing.T) {
+	dir := t.TempDir()
+	fake := &fakeGit{}
+	cache := New(dir, fake)
+	repo := testRepo("octo", "repo")
+
+	path, err := cache.Ensure(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("ensure: %v", err)
+	}
+	if want := filepath.Join(dir, "github.com", "octo", "repo.git"); path != want {
+		t.Fatalf("expected mirror at %s, got %s", want, path)
+	}
+	if len(fake.clones) != 1 || fake.clones[0] != repo.CloneURL || len(fake.fetches) != 0 {
+		t.Fatalf("expected one mirror clone, got clones=%v fetches=%v", fake.clones, fake.fetches)
+	}
+	if !pathExists(filepath.Join(path, lastUsedFile)) {
+		t.Fatalf("expected last-used marker")
+	}
+
+	if _, err := cache.Ensure(context.Background(), repo); err != nil {
+		t.Fatalf("ensure again: %v", err)
+	}
+	if len(fake.clones) != 1 || len(fake.fetches) != 1 || fake.fetches[0] != path {
+		t.Fatalf("expected the existing mirror to be fetched, got clones=%v fetches=%v", fake.clones, fake.fetches)
+	}
+}
+
+func TestEnsureLeavesNothingBehindOnFailedClone(t *testing.T) {
+	dir := t.TempDir()
+	cache := New(dir, &fakeGit{cloneErr: errors.New("network down")})
+	if _, err := cache.Ensure(context.Background(), testRepo("octo", "repo")); err == nil {
+		t.Fatalf("expected clone error")
+	}
+	entries, err := os.ReadDir(filepath.Join(dir, "github.com", "octo"))
+	if err != nil {
+		t.Fatalf("read cache dir: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("expected no partial mirror, got %v", entries)
+	}
+}
+
+func TestListAndPrune(t *testing.T) {
+	dir := t.TempDir()
+	fake := &fakeGit{}
+	cache := New(dir, fake)
+	fresh, err := cache.Ensure(context.Background(), testRepo("octo", "fresh"))
+	if err != nil {
+		t.Fatalf("ensure fresh: %v", err)
+	}
+	stale, err := cache.Ensure(context.Background(), testRepo("octo", "stale"))
+	if err != nil {
+		t.Fatalf("ensure stale: %v", err)
+	}
+	old := time.Now().Add(-60 * 24 * time.Hour)
+	if err := os.Chtimes(filepath.Join(stale, lastUsedFile), old, old); err != nil {
+		t.Fatalf("age marker: %v", err)
+	}
+
+	mirrors, err := cache.List()
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(mirrors) != 2 || mirrors[0].Repo != "github.com/octo/fresh" || mirrors[1].Repo != "github.com/octo/stale" {
+		t.Fatalf("unexpected mirrors: %+v", mirrors)
+	}
+	if mirrors[0].Size == 0 {
+		t.Fatalf("expected a non-zero size, got %+v", mirrors[0])
+	}
+
+	pruned, err := cache.Prune(30*24*time.Hour, true)
+	if err != nil {
+		t.Fatalf("dry-run prune: %v", err)
+	}
+	if len(pruned) != 1 || pruned[0].Path != stale || !pathExists(stale) {
+		t.Fatalf("expected a dry run to report only the stale mirror, got %+v", pruned)
+	}
+
+	if _, err := cache.Prune(30*24*time.Hour, false); err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+	if pathExists(stale) || !pathExists(fresh) {
+		t.Fatalf("expected only the stale mirror to be removed")
+	}
+
+	if _, err := cache.Prune(0, false); err != nil {
+		t.Fatalf("prune all: %v", err)
+	}
+	if pathExists(fresh) {
+		t.Fatalf("expected every mirror to be removed")
+	}
+}
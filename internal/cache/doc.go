@@ -0,0 +1,2 @@
+// Package cache maintains local mirrors that new clones borrow objects from.
+package cache
@@ -0,0 +1,232 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"text/tabwriter"
+	"time"
+
+	"github.com/BradyPlanden/prt/internal/cache"
+	"github.com/BradyPlanden/prt/internal/config"
+	"github.com/BradyPlanden/prt/internal/git"
+	"github.com/spf13/cobra"
+)
+
+type cacheListOptions struct {
+	JSON bool
+}
+
+type cachePruneOptions struct {
+	OlderThan time.Duration
+	All       bool
+	DryRun    bool
+}
+
+type cacheEntry struct {
+	Repo     string     `json:"repo"`
+	Path     string     `json:"path"`
+	Size     int64      `json:"size"`
+	LastUsed *time.Time `json:"last_used,omitempty"`
+}
+
+func newCacheCommand(rootOpts *rootOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the mirror cache that new clones copy objects from",
+		Example: "" +
+			"  prt cache list\n" +
+			"  prt cache prune --older-than 720h\n" +
+			"  prt cache gc",
+		Args: cobra.NoArgs,
+	}
+	cmd.AddCommand(newCacheListCommand(rootOpts))
+	cmd.AddCommand(newCachePruneCommand(rootOpts))
+	cmd.AddCommand(newCacheGCCommand(rootOpts))
+	return cmd
+}
+
+func newCacheListCommand(rootOpts *rootOptions) *cobra.Command {
+	opts := &cacheListOptions{}
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List cached mirrors and their size on disk",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runCacheList(cmd, rootOpts, opts)
+		},
+	}
+	cmd.Flags().BoolVar(&opts.JSON, "json", false, "Print mirrors as JSON")
+	return cmd
+}
+
+func newCachePruneCommand(rootOpts *rootOptions) *cobra.Command {
+	opts := &cachePruneOptions{}
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove mirrors no clone has used recently",
+		Example: "" +
+			"  prt cache prune --dry-run\n" +
+			"  prt cache prune --older-than 168h\n" +
+			"  prt cache prune --all",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runCachePrune(cmd, rootOpts, opts)
+		},
+	}
+	cmd.Flags().DurationVar(&opts.OlderThan, "older-than", 30*24*time.Hour, "Remove mirrors unused for this long")
+	cmd.Flags().BoolVar(&opts.All, "all", false, "Remove every mirror")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Show what would be removed")
+	return cmd
+}
+
+func newCacheGCCommand(rootOpts *rootOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "gc",
+		Short: "Run git gc in every cached mirror",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runCacheGC(cmd, rootOpts)
+		},
+	}
+}
+
+// newMirrorCache returns the configured mirror cache, or nil when cache_dir
+// is unset.
+func newMirrorCache(cfg config.Config, gitClient *git.Client) *cache.Cache {
+	if cfg.CacheDir == "" {
+		return nil
+	}
+	return cache.New(cfg.CacheDir, gitClient)
+}
+
+func loadMirrorCache(cmd *cobra.Command, rootOpts *rootOptions) (*cache.Cache, error) {
+	cfg, err := loadConfig(rootOpts)
+	if err != nil {
+		return nil, err
+	}
+	gitClient := git.NewClient(git.ClientOptions{
+		Verbose: cfg.Verbose,
+		Logger:  log.New(cmd.ErrOrStderr(), "", 0),
+	})
+	mirrors := newMirrorCache(cfg, gitClient)
+	if mirrors == nil {
+		return nil, fmt.Errorf("no mirror cache configured (set cache_dir or PRT_CACHE_DIR)")
+	}
+	return mirrors, nil
+}
+
+func runCacheList(cmd *cobra.Command, rootOpts *rootOptions, opts *cacheListOptions) error {
+	mirrors, err := loadMirrorCache(cmd, rootOpts)
+	if err != nil {
+		return err
+	}
+	list, err := mirrors.List()
+	if err != nil {
+		return err
+	}
+
+	entries := make([]cacheEntry, 0, len(list))
+	var total int64
+	for _, mirror := range list {
+		entry := cacheEntry{Repo: mirror.Repo, Path: mirror.Path, Size: mirror.Size}
+		if !mirror.LastUsed.IsZero() {
+			lastUsed := mirror.LastUsed
+			entry.LastUsed = &lastUsed
+		}
+		entries = append(entries, entry)
+		total += mirror.Size
+	}
+
+	if opts.JSON {
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(entries)
+	}
+
+	if len(entries) == 0 {
+		fmt.Fprintf(cmd.ErrOrStderr(), "No mirrors in %s\n", mirrors.Dir())
+		return nil
+	}
+
+	writer := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "REPO\tSIZE\tLAST USED\tPATH")
+	for _, entry := range entries {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", entry.Repo, formatSize(entry.Size), lastUsedLabel(entry.LastUsed), entry.Path)
+	}
+	fmt.Fprintf(writer, "TOTAL\t%s\t\t\n", formatSize(total))
+	return writer.Flush()
+}
+
+func runCachePrune(cmd *cobra.Command, rootOpts *rootOptions, opts *cachePruneOptions) error {
+	mirrors, err := loadMirrorCache(cmd, rootOpts)
+	if err != nil {
+		return err
+	}
+	olderThan := opts.OlderThan
+	if opts.All {
+		olderThan = 0
+	} else if olderThan <= 0 {
+		return fmt.Errorf("--older-than must be positive (use --all to remove every mirror)")
+	}
+
+	pruned, err := mirrors.Prune(olderThan, opts.DryRun)
+	for _, mirror := range pruned {
+		if opts.DryRun {
+			fmt.Fprintf(cmd.OutOrStdout(), "Would remove %s (%s)\n", mirror.Path, formatSize(mirror.Size))
+			continue
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Removed %s (%s)\n", mirror.Path, formatSize(mirror.Size))
+	}
+	if err != nil {
+		return err
+	}
+	if len(pruned) == 0 {
+		fmt.Fprintln(cmd.ErrOrStderr(), "No mirrors to remove")
+	}
+	return nil
+}
+
+func runCacheGC(cmd *cobra.Command, rootOpts *rootOptions) error {
+	mirrors, err := loadMirrorCache(cmd, rootOpts)
+	if err != nil {
+		return err
+	}
+	list, err := mirrors.List()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := withDefaultTimeout(cmd.Context())
+	defer cancel()
+
+	var failed int
+	for _, mirror := range list {
+		size, err := mirrors.GC(ctx, mirror)
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Warning: gc failed for %s: %v\n", mirror.Path, err)
+			failed++
+			continue
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%s: %s -> %s\n", mirror.Repo, formatSize(mirror.Size), formatSize(size))
+	}
+	if failed > 0 {
+		return fmt.Errorf("gc failed for %d mirror(s)", failed)
+	}
+	return nil
+}
+
+func formatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	value := float64(bytes) / unit
+	for _, suffix := range []string{"KiB", "MiB", "GiB"} {
+		if value < unit {
+			return fmt.Sprintf("%.1f %s", value, suffix)
+		}
+		value /= unit
+	}
+	return fmt.Sprintf("%.1f TiB", value)
+}
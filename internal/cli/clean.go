@@ -1,8 +1,10 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/BradyPlanden/prt/internal/git"
@@ -11,8 +13,11 @@ import (
 )
 
 type cleanOptions struct {
-	All    bool
-	DryRun bool
+	All        bool
+	DryRun     bool
+	Persistent bool
+	Merged     bool
+	Closed     bool
 }
 
 func newCleanCommand(rootOpts *rootOptions) *cobra.Command {
@@ -20,10 +25,12 @@ func newCleanCommand(rootOpts *rootOptions) *cobra.Command {
 
 	cmd := &cobra.Command{
 		Use:   "clean",
-		Short: "Remove old temporary worktrees",
+		Short: "Remove old temporary or finished persistent worktrees",
 		Example: "" +
 			"  prt clean --dry-run\n" +
-			"  prt clean --all",
+			"  prt clean --all\n" +
+			"  prt clean --merged --closed\n" +
+			"  prt clean --persistent --persistent-ttl 720h",
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			return runClean(cmd, rootOpts, opts)
 		},
@@ -31,6 +38,10 @@ func newCleanCommand(rootOpts *rootOptions) *cobra.Command {
 
 	cmd.Flags().BoolVar(&opts.All, "all", false, "Remove all temp worktrees")
 	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Show what would be removed")
+	cmd.Flags().BoolVar(&opts.Persistent, "persistent", false, "Clean persistent worktrees of merged, closed, or idle PRs")
+	cmd.Flags().BoolVar(&opts.Merged, "merged", false, "Remove worktrees whose PR is merged, regardless of age")
+	cmd.Flags().BoolVar(&opts.Closed, "closed", false, "Remove worktrees whose PR is closed without merging, regardless of age")
+	cmd.Flags().StringVar(&rootOpts.PersistentTTL, "persistent-ttl", "", "Also remove persistent worktrees idle this long (e.g. 720h)")
 
 	return cmd
 }
@@ -53,21 +64,79 @@ func runClean(cmd *cobra.Command, rootOpts *rootOptions, opts *cleanOptions) err
 	ctx, cancel := withDefaultTimeout(cmd.Context())
 	defer cancel()
 
-	var ttl time.Duration
-	if !opts.All {
-		ttl = cfg.TempTTL
+	var states []string
+	if opts.Merged {
+		states = append(states, "MERGED")
+	}
+	if opts.Closed {
+		states = append(states, "CLOSED")
+	}
+	if opts.All && (opts.Persistent || len(states) > 0) {
+		return fmt.Errorf("--all cannot be combined with --persistent, --merged, or --closed")
 	}
 
-	results, err := resolver.CleanTemp(ctx, cfg.TempDir, ttl, opts.All, opts.DryRun)
+	var prState workspace.PRStateFunc
+	if opts.Persistent || len(states) > 0 {
+		ghClient, err := newGitHubClient(cfg)
+		if err != nil {
+			return err
+		}
+		prState = func(ctx context.Context, info workspace.WorktreeInfo) (string, error) {
+			meta, err := ghClient.FetchPRMetadata(ctx, info.PRURL())
+			if err != nil {
+				return "", err
+			}
+			return strings.ToUpper(meta.State), nil
+		}
+	}
+
+	var results []workspace.CleanResult
+	if opts.Persistent {
+		results, err = resolver.CleanPersistent(ctx, cfg.ProjectsDir, workspace.PersistentCleanOptions{
+			TTL:     cfg.PersistentTTL,
+			DryRun:  opts.DryRun,
+			PRState: prState,
+			States:  states,
+		})
+	} else {
+		var ttl time.Duration
+		if !opts.All {
+			ttl = cfg.TempTTL
+		}
+		results, err = resolver.CleanTempWithOptions(ctx, cfg.TempDir, workspace.TempCleanOptions{
+			TTL:     ttl,
+			All:     opts.All,
+			DryRun:  opts.DryRun,
+			States:  states,
+			PRState: prState,
+		})
+	}
 	if err != nil {
 		return err
 	}
 
 	for _, result := range results {
+		switch {
+		case result.Branch != "":
+			printBranchOrRemoteResult(cmd, result, "branch", result.Branch)
+			continue
+		case result.Remote != "":
+			printBranchOrRemoteResult(cmd, result, "remote", result.Remote)
+			continue
+		}
+
 		switch result.Action {
 		case workspace.CleanActionWouldRemove:
+			if result.Reason != "" {
+				fmt.Fprintf(cmd.OutOrStdout(), "Would remove %s (%s)\n", result.Path, result.Reason)
+				continue
+			}
 			fmt.Fprintf(cmd.OutOrStdout(), "Would remove %s\n", result.Path)
 		case workspace.CleanActionRemoved:
+			if result.Reason != "" {
+				fmt.Fprintf(cmd.OutOrStdout(), "Removed %s (%s)\n", result.Path, result.Reason)
+				continue
+			}
 			fmt.Fprintf(cmd.OutOrStdout(), "Removed %s\n", result.Path)
 		case workspace.CleanActionWouldPrune:
 			fmt.Fprintf(cmd.OutOrStdout(), "Would prune missing worktree metadata for %s\n", result.Path)
@@ -84,3 +153,14 @@ func runClean(cmd *cobra.Command, rootOpts *rootOptions, opts *cleanOptions) err
 
 	return nil
 }
+
+func printBranchOrRemoteResult(cmd *cobra.Command, result workspace.CleanResult, kind string, name string) {
+	switch result.Action {
+	case workspace.CleanActionWouldRemove:
+		fmt.Fprintf(cmd.OutOrStdout(), "Would delete %s %s in %s\n", kind, name, result.Path)
+	case workspace.CleanActionRemoved:
+		fmt.Fprintf(cmd.OutOrStdout(), "Deleted %s %s in %s\n", kind, name, result.Path)
+	case workspace.CleanActionSkipped:
+		fmt.Fprintf(cmd.OutOrStdout(), "Kept %s %s in %s (%s)\n", kind, name, result.Path, result.Reason)
+	}
+}
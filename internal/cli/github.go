@@ -0,0 +1,14 @@
+package cli
+
+import (
+	"github.com/BradyPlanden/prt/internal/config"
+	"github.com/BradyPlanden/prt/internal/github"
+)
+
+func newGitHubClient(cfg config.Config) (github.MetadataClient, error) {
+	return github.NewMetadataClient(github.BackendOptions{
+		Backend: cfg.GitHubBackend,
+		Verbose: cfg.Verbose,
+		Hosts:   cfg.GitHubHosts,
+	})
+}
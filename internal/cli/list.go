@@ -0,0 +1,196 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/BradyPlanden/prt/internal/git"
+	"github.com/BradyPlanden/prt/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+type listOptions struct {
+	JSON    bool
+	Offline bool
+}
+
+type listEntry struct {
+	Repo     string     `json:"repo"`
+	Number   int        `json:"number"`
+	URL      string     `json:"url,omitempty"`
+	Title    string     `json:"title,omitempty"`
+	Branch   string     `json:"branch"`
+	Path     string     `json:"path"`
+	Mode     string     `json:"mode"`
+	Dirty    bool       `json:"dirty"`
+	Missing  bool       `json:"missing,omitempty"`
+	LastUsed *time.Time `json:"last_used,omitempty"`
+	State    string     `json:"state,omitempty"`
+}
+
+func newListCommand(rootOpts *rootOptions) *cobra.Command {
+	opts := &listOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List PR worktrees managed by prt",
+		Example: "" +
+			"  prt list\n" +
+			"  prt list --json",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runList(cmd, rootOpts, opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.JSON, "json", false, "Print worktrees as JSON")
+	cmd.Flags().BoolVar(&opts.Offline, "offline", false, "Skip looking up PR state on GitHub")
+
+	return cmd
+}
+
+func runList(cmd *cobra.Command, rootOpts *rootOptions, opts *listOptions) error {
+	cfg, err := loadConfig(rootOpts)
+	if err != nil {
+		return err
+	}
+
+	logger := log.New(cmd.ErrOrStderr(), "", 0)
+	gitClient := git.NewClient(git.ClientOptions{
+		Verbose: cfg.Verbose,
+		Logger:  logger,
+	})
+	resolver := workspace.NewResolver(gitClient, workspace.ResolverOptions{
+		Logger: logger,
+	})
+
+	ctx, cancel := withDefaultTimeout(cmd.Context())
+	defer cancel()
+
+	infos, err := resolver.List(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	entries := make([]listEntry, 0, len(infos))
+	for _, info := range infos {
+		entry := listEntry{
+			Repo:    repoName(info),
+			Number:  info.Number,
+			URL:     info.PRURL(),
+			Title:   info.Title,
+			Branch:  info.Branch,
+			Path:    info.Path,
+			Mode:    "persistent",
+			Dirty:   info.Dirty,
+			Missing: info.Missing,
+		}
+		if info.Temp {
+			entry.Mode = "temp"
+		}
+		if !info.LastUsed.IsZero() {
+			lastUsed := info.LastUsed
+			entry.LastUsed = &lastUsed
+		}
+		entries = append(entries, entry)
+	}
+
+	if !opts.Offline {
+		ghClient, err := newGitHubClient(cfg)
+		if err != nil {
+			return err
+		}
+		states := make(map[string]string)
+		for i := range entries {
+			if entries[i].URL == "" {
+				continue
+			}
+			state, ok := states[entries[i].URL]
+			if !ok {
+				state = "UNKNOWN"
+				meta, err := ghClient.FetchPRMetadata(ctx, entries[i].URL)
+				if err != nil {
+					fmt.Fprintf(cmd.ErrOrStderr(), "Warning: could not look up %s: %v\n", entries[i].URL, err)
+				} else {
+					state = strings.ToUpper(meta.State)
+				}
+				states[entries[i].URL] = state
+			}
+			entries[i].State = state
+		}
+	}
+
+	if opts.JSON {
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(entries)
+	}
+
+	if len(entries) == 0 {
+		fmt.Fprintln(cmd.ErrOrStderr(), "No prt worktrees found")
+		return nil
+	}
+
+	writer := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "REPO\tPR\tBRANCH\tMODE\tSTATE\tDIRTY\tLAST USED\tPATH")
+	for _, entry := range entries {
+		fmt.Fprintf(writer, "%s\t#%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			entry.Repo,
+			entry.Number,
+			valueOrDash(entry.Branch),
+			entry.Mode,
+			valueOrDash(entry.State),
+			dirtyLabel(entry),
+			lastUsedLabel(entry.LastUsed),
+			entry.Path,
+		)
+	}
+	return writer.Flush()
+}
+
+func repoName(info workspace.WorktreeInfo) string {
+	if info.Repo.Owner == "" || info.Repo.Name == "" {
+		return "-"
+	}
+	return info.Repo.Owner + "/" + info.Repo.Name
+}
+
+func dirtyLabel(entry listEntry) string {
+	switch {
+	case entry.Missing:
+		return "missing"
+	case entry.Dirty:
+		return "yes"
+	default:
+		return "no"
+	}
+}
+
+func lastUsedLabel(lastUsed *time.Time) string {
+	if lastUsed == nil {
+		return "-"
+	}
+	age := time.Since(*lastUsed)
+	switch {
+	case age < time.Minute:
+		return "just now"
+	case age < time.Hour:
+		return strconv.Itoa(int(age.Minutes())) + "m ago"
+	case age < 48*time.Hour:
+		return strconv.Itoa(int(age.Hours())) + "h ago"
+	default:
+		return strconv.Itoa(int(age.Hours()/24)) + "d ago"
+	}
+}
+
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -1,11 +1,14 @@
 package cli
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
 	"strings"
+	"sync"
 
+	"github.com/BradyPlanden/prt/internal/config"
 	"github.com/BradyPlanden/prt/internal/git"
 	"github.com/BradyPlanden/prt/internal/github"
 	"github.com/BradyPlanden/prt/internal/terminal"
@@ -13,62 +16,316 @@ import (
 	"github.com/spf13/cobra"
 )
 
-func runOpen(cmd *cobra.Command, opts *rootOptions, prURL string) error {
+func runOpen(cmd *cobra.Command, opts *rootOptions, args []string) error {
 	cfg, err := loadConfig(opts)
 	if err != nil {
 		return err
 	}
+	// An explicit --update or --update=false wins over update_on_reuse.
+	if cmd.Flags().Changed("update") {
+		cfg.UpdateOnReuse = opts.Update
+	}
+
+	session, err := newOpenSession(cmd, cfg, opts)
+	if err != nil {
+		return err
+	}
+
+	var refs []github.PRRef
+	if len(args) == 0 && opts.From == "" {
+		ref, err := pickCurrentRepoPR(cmd, cfg, session.github)
+		if err != nil {
+			return err
+		}
+		refs = []github.PRRef{ref}
+	}
 
 	ctx, cancel := withDefaultTimeout(cmd.Context())
 	defer cancel()
 
-	ghClient := github.NewClient(github.ClientOptions{Verbose: cfg.Verbose})
-	meta, err := ghClient.FetchPRMetadata(ctx, prURL)
+	if refs == nil {
+		if refs, err = collectPRReferences(ctx, cfg, args, opts.From, cmd.InOrStdin()); err != nil {
+			return err
+		}
+	}
+	if len(refs) > 1 {
+		return session.openBatch(refs)
+	}
+
+	ref := refs[0]
+	var commitMode string
+	if ref.Commit != "" {
+		if commitMode, err = chooseCommitMode(cmd, opts.CommitMode, ref.Commit); err != nil {
+			return err
+		}
+	}
+	pr, err := session.resolve(ctx, ref, commitMode, log.New(cmd.ErrOrStderr(), "", 0))
 	if err != nil {
 		return err
 	}
+	session.open(ctx, pr)
+	return nil
+}
+
+// openSession holds what every PR opened by one invocation shares.
+type openSession struct {
+	cmd     *cobra.Command
+	opts    *rootOptions
+	cfg     config.Config
+	github  github.MetadataClient
+	confirm workspace.ConfirmFunc
+}
+
+// resolvedPR is a PR whose worktree is ready to open.
+type resolvedPR struct {
+	meta     github.PRMetadata
+	result   workspace.Result
+	resolver *workspace.Resolver
+}
+
+func newOpenSession(cmd *cobra.Command, cfg config.Config, opts *rootOptions) (*openSession, error) {
+	if opts.Jobs < 1 {
+		return nil, fmt.Errorf("--jobs must be at least 1")
+	}
+	ghClient, err := newGitHubClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	session := &openSession{cmd: cmd, opts: opts, cfg: cfg, github: ghClient}
+	if isInteractive(cmd) {
+		// Prompts from parallel resolves are asked one at a time.
+		var promptMu sync.Mutex
+		session.confirm = func(question string) (bool, error) {
+			promptMu.Lock()
+			defer promptMu.Unlock()
+			choice, err := promptChoice(cmd, question, []promptOption{
+				{Key: "y", Label: "yes"},
+				{Key: "n", Label: "no"},
+			}, "n")
+			return choice == "y", err
+		}
+	}
+	return session, nil
+}
+
+// resolve fetches ref's metadata and prepares its worktree, reporting
+// warnings through logger. commitMode is how a /commits/<sha> reference is
+// checked out (see chooseCommitMode).
+func (s *openSession) resolve(ctx context.Context, ref github.PRRef, commitMode string, logger *log.Logger) (resolvedPR, error) {
+	meta, err := s.github.FetchPRMetadata(ctx, ref.URL())
+	if err != nil {
+		return resolvedPR{}, err
+	}
+
+	wsOpts := workspace.Options{
+		Temp:         s.opts.Temp,
+		OnForcePush:  workspace.ForcePushPolicy(s.cfg.OnForcePush),
+		Force:        s.opts.Force,
+		Update:       s.cfg.UpdateOnReuse,
+		NoSubmodules: s.opts.NoSubmodules,
+	}
+	if ref.Commit != "" && commitMode != "head" {
+		wsOpts.Commit = ref.Commit
+		wsOpts.CommitMode = workspace.CommitMode(commitMode)
+	}
 
 	if strings.EqualFold(meta.State, "CLOSED") || strings.EqualFold(meta.State, "MERGED") {
-		fmt.Fprintf(cmd.ErrOrStderr(), "Warning: PR is %s: %s\n", strings.ToUpper(meta.State), meta.URL)
+		logger.Printf("Warning: PR is %s: %s", strings.ToUpper(meta.State), meta.URL)
 	}
 
-	logger := log.New(cmd.ErrOrStderr(), "", 0)
 	gitClient := git.NewClient(git.ClientOptions{
-		Verbose: cfg.Verbose,
+		Verbose: s.cfg.Verbose,
 		Logger:  logger,
 	})
-
-	resolver := workspace.NewResolver(gitClient, workspace.ResolverOptions{
-		Logger: logger,
-	})
-	result, err := resolver.Resolve(ctx, cfg, meta, workspace.Options{Temp: opts.Temp})
+	resolverOpts := workspace.ResolverOptions{Logger: logger, Confirm: s.confirm}
+	if mirrors := newMirrorCache(s.cfg, gitClient); mirrors != nil {
+		resolverOpts.Cache = mirrors
+	}
+	resolver := workspace.NewResolver(gitClient, resolverOpts)
+	result, err := resolver.Resolve(ctx, s.cfg, meta, wsOpts)
 	if err != nil {
-		return err
+		return resolvedPR{}, err
+	}
+	return resolvedPR{meta: meta, result: result, resolver: resolver}, nil
+}
+
+// openBatch resolves refs with up to --jobs workers, then opens the ready
+// worktrees in the order given and reports each PR's outcome.
+func (s *openSession) openBatch(refs []github.PRRef) error {
+	stderr := s.cmd.ErrOrStderr()
+
+	// Commit links are settled first so prompts are not interleaved.
+	commitModes := make([]string, len(refs))
+	for i, ref := range refs {
+		if ref.Commit == "" {
+			continue
+		}
+		mode, err := chooseCommitMode(s.cmd, s.opts.CommitMode, ref.Commit)
+		if err != nil {
+			return err
+		}
+		commitModes[i] = mode
 	}
 
-	if opts.NoTab {
-		fmt.Fprintln(cmd.OutOrStdout(), result.Path)
-		return nil
+	jobs := min(s.opts.Jobs, len(refs))
+	fmt.Fprintf(stderr, "Resolving %d PRs (%d at a time)\n", len(refs), jobs)
+
+	resolved := make([]resolvedPR, len(refs))
+	errs := make([]error, len(refs))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range jobs {
+		wg.Go(func() {
+			for i := range indexes {
+				ctx, cancel := withDefaultTimeout(s.cmd.Context())
+				logger := log.New(stderr, refLabel(refs[i])+": ", 0)
+				resolved[i], errs[i] = s.resolve(ctx, refs[i], commitModes[i], logger)
+				cancel()
+			}
+		})
 	}
+	for i := range refs {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
 
-	termCfg := terminal.Config{Terminal: cfg.Terminal}
-	opener, err := terminal.Detect(termCfg)
-	if err != nil {
-		fmt.Fprintf(cmd.ErrOrStderr(), "Terminal detection failed: %v\n", err)
-		fmt.Fprintln(cmd.OutOrStdout(), result.Path)
-		return nil
+	var failed int
+	for i, ref := range refs {
+		if errs[i] != nil {
+			fmt.Fprintf(stderr, "%s: failed: %v\n", refLabel(ref), errs[i])
+			failed++
+			continue
+		}
+		fmt.Fprintf(stderr, "%s: ready at %s\n", refLabel(ref), resolved[i].result.Path)
+		ctx, cancel := withDefaultTimeout(s.cmd.Context())
+		s.open(ctx, resolved[i])
+		cancel()
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d PRs failed", failed, len(refs))
+	}
+	return nil
+}
+
+// open shows pr's worktree in a terminal tab, an editor, or both, as
+// configured, printing its path when neither is used or opening fails.
+func (s *openSession) open(ctx context.Context, pr resolvedPR) {
+	cmd, opts, cfg := s.cmd, s.opts, s.cfg
+	meta, result := pr.meta, pr.result
+
+	openWith := cfg.OpenWith
+	// --editor and --editor-cmd imply open_with: editor unless --open-with
+	// says otherwise.
+	if (opts.Editor || opts.EditorCmd != "") && opts.OpenWith == "" && openWith == "terminal" {
+		openWith = "editor"
+	}
+
+	printed := false
+	printPath := func() {
+		if !printed {
+			fmt.Fprintln(cmd.OutOrStdout(), result.Path)
+			printed = true
+		}
 	}
 
-	if err := opener.Open(result.Path); err != nil {
+	var openers []terminal.TabOpener
+	if openWith != "editor" {
+		termCfg := terminal.Config{
+			Terminal: cfg.Terminal,
+			Name:     fmt.Sprintf("%s-pr-%d", meta.BaseRepo.Name, meta.Number),
+			Command:  cfg.TerminalCommand,
+			Shell:    cfg.TerminalShell,
+			PR: terminal.PRInfo{
+				Number: meta.Number,
+				Title:  meta.Title,
+				Repo:   meta.BaseRepo.Owner + "/" + meta.BaseRepo.Name,
+				Branch: meta.HeadRef,
+			},
+		}
+		if opts.NoTab {
+			printPath()
+		} else if opener, err := terminal.Detect(termCfg); err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Terminal detection failed: %v\n", err)
+			printPath()
+		} else {
+			openers = append(openers, opener)
+		}
+	}
+	if openWith != "terminal" {
+		editorCfg := terminal.EditorConfig{Editor: cfg.Editor}
+		if opts.FirstChanged || cfg.EditorFirstChanged {
+			files, err := pr.resolver.PRFiles(ctx, result.Path)
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Warning: could not list changed files: %v\n", err)
+			} else if len(files) > 0 {
+				editorCfg.File = files[0]
+			}
+		}
+		if opener, err := terminal.DetectEditor(editorCfg); err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Editor detection failed: %v\n", err)
+			printPath()
+		} else {
+			openers = append(openers, opener)
+		}
+	}
+	if len(openers) == 0 {
+		return
+	}
+
+	if err := terminal.Combine(openers...).Open(result.Path); err != nil {
 		var permErr terminal.PermissionError
 		if errors.As(err, &permErr) {
 			fmt.Fprintf(cmd.ErrOrStderr(), "Automation permission error: %v\n", permErr)
 		} else {
-			fmt.Fprintf(cmd.ErrOrStderr(), "Failed to open terminal tab: %v\n", err)
+			fmt.Fprintf(cmd.ErrOrStderr(), "Failed to open %s: %v\n", openWithLabel(openWith), err)
 		}
-		fmt.Fprintln(cmd.OutOrStdout(), result.Path)
-		return nil
+		printPath()
 	}
+}
 
-	return nil
+func openWithLabel(openWith string) string {
+	switch openWith {
+	case "editor":
+		return "editor"
+	case "both":
+		return "terminal tab or editor"
+	default:
+		return "terminal tab"
+	}
+}
+
+// chooseCommitMode decides how to check out a /commits/<sha> link: the PR
+// head, the commit detached, or the commit on its own branch.
+func chooseCommitMode(cmd *cobra.Command, flagValue string, commit string) (string, error) {
+	switch mode := strings.ToLower(strings.TrimSpace(flagValue)); mode {
+	case "head", string(workspace.CommitModeDetach), string(workspace.CommitModeBranch):
+		return mode, nil
+	case "":
+	default:
+		return "", fmt.Errorf("invalid --commit-mode %q (expected head, detach, or branch)", flagValue)
+	}
+
+	short := workspace.ShortSHA(commit)
+	if !isInteractive(cmd) {
+		fmt.Fprintf(cmd.ErrOrStderr(), "URL points at commit %s; checking out the PR head (use --commit-mode detach|branch to check out the commit)\n", short)
+		return "head", nil
+	}
+
+	choice, err := promptChoice(cmd, fmt.Sprintf("URL points at commit %s. What should be checked out?", short), []promptOption{
+		{Key: "h", Label: "PR head"},
+		{Key: "d", Label: "this commit (detached HEAD)"},
+		{Key: "b", Label: "this commit on a new branch"},
+	}, "h")
+	if err != nil {
+		return "", err
+	}
+	switch choice {
+	case "d":
+		return string(workspace.CommitModeDetach), nil
+	case "b":
+		return string(workspace.CommitModeBranch), nil
+	default:
+		return "head", nil
+	}
 }
@@ -0,0 +1,220 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+
+	"github.com/BradyPlanden/prt/internal/config"
+	"github.com/BradyPlanden/prt/internal/github"
+	"github.com/spf13/cobra"
+)
+
+var (
+	errNoPRSelected = errors.New("no PR selected")
+	errMissingPRRef = errors.New("missing PR reference argument (run 'prt --help')")
+)
+
+// pickCurrentRepoPR lets the user choose one of the open PRs of the
+// repository in the current directory, for runs without a PR argument. It
+// uses fzf when it is installed and the output is a terminal, and a
+// numbered menu otherwise.
+func pickCurrentRepoPR(cmd *cobra.Command, cfg config.Config, client github.MetadataClient) (github.PRRef, error) {
+	cwd, err := os.Getwd()
+	if err != nil || (!isWithin(cwd, cfg.ProjectsDir) && !isWithin(cwd, cfg.TempDir)) {
+		return github.PRRef{}, errMissingPRRef
+	}
+
+	ctx, cancel := withDefaultTimeout(cmd.Context())
+	defer cancel()
+	repo, err := currentRepository(ctx, cfg)
+	if err != nil {
+		return github.PRRef{}, err
+	}
+	prs, err := client.ListPRs(ctx, repo, github.DefaultListLimit)
+	if err != nil {
+		return github.PRRef{}, fmt.Errorf("list open PRs: %w", err)
+	}
+	if len(prs) == 0 {
+		return github.PRRef{}, fmt.Errorf("no open PRs in %s/%s", repo.Owner, repo.Name)
+	}
+
+	rows := prRows(prs)
+	title := fmt.Sprintf("%s/%s", repo.Owner, repo.Name)
+	var number int
+	if _, lookErr := exec.LookPath("fzf"); lookErr == nil && isTerminal(cmd.OutOrStdout()) && isTerminal(cmd.ErrOrStderr()) {
+		number, err = pickWithFzf(ctx, title, rows)
+	} else {
+		number, err = pickFromMenu(cmd, title, prs, rows)
+	}
+	if err != nil {
+		return github.PRRef{}, err
+	}
+	return github.PRRef{Host: repo.Host, Owner: repo.Owner, Repo: repo.Name, Number: number}, nil
+}
+
+// prRows formats prs as aligned lines starting with "#<number>".
+func prRows(prs []github.PRSummary) []string {
+	var buf bytes.Buffer
+	writer := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	for _, pr := range prs {
+		draft := ""
+		if pr.Draft {
+			draft = "draft"
+		}
+		fmt.Fprintf(writer, "#%d\t%s\t@%s\t%s\t%s\t%s\n",
+			pr.Number,
+			truncate(pr.Title, 60),
+			valueOrDash(pr.Author),
+			draft,
+			reviewLabel(pr.ReviewDecision),
+			checksLabel(pr.Checks),
+		)
+	}
+	writer.Flush()
+	return strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+}
+
+func reviewLabel(decision string) string {
+	switch decision {
+	case "APPROVED":
+		return "approved"
+	case "CHANGES_REQUESTED":
+		return "changes requested"
+	case "REVIEW_REQUIRED":
+		return "review required"
+	default:
+		return ""
+	}
+}
+
+func checksLabel(checks string) string {
+	switch checks {
+	case github.ChecksSuccess:
+		return "checks passing"
+	case github.ChecksFailure:
+		return "checks failing"
+	case github.ChecksPending:
+		return "checks pending"
+	default:
+		return ""
+	}
+}
+
+func truncate(value string, limit int) string {
+	if utf8.RuneCountInString(value) <= limit {
+		return value
+	}
+	runes := []rune(value)
+	return string(runes[:limit-1]) + "…"
+}
+
+// pickWithFzf shows rows in fzf, which draws on the terminal itself, and
+// returns the chosen PR number.
+func pickWithFzf(ctx context.Context, title string, rows []string) (int, error) {
+	fzf := exec.CommandContext(ctx, "fzf", "--no-multi", "--layout=reverse", "--prompt", title+"> ", "--header", "Select a PR to open")
+	fzf.Stdin = strings.NewReader(strings.Join(rows, "\n") + "\n")
+	fzf.Stderr = os.Stderr
+	output, err := fzf.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		// fzf exits 1 with no match and 130 when cancelled.
+		if errors.As(err, &exitErr) && (exitErr.ExitCode() == 1 || exitErr.ExitCode() == 130) {
+			return 0, errNoPRSelected
+		}
+		return 0, fmt.Errorf("run fzf: %w", err)
+	}
+	number, ok := rowNumber(string(output))
+	if !ok {
+		return 0, errNoPRSelected
+	}
+	return number, nil
+}
+
+func rowNumber(row string) (int, bool) {
+	fields := strings.Fields(row)
+	if len(fields) == 0 {
+		return 0, false
+	}
+	return github.ParsePRNumber(fields[0])
+}
+
+// pickFromMenu lists rows on stderr and reads the choice from stdin: a PR
+// number opens it, other text narrows the list to rows matching it fuzzily,
+// and an empty line cancels. It returns errMissingPRRef when stdin has no
+// input at all.
+func pickFromMenu(cmd *cobra.Command, title string, prs []github.PRSummary, rows []string) (int, error) {
+	out := cmd.ErrOrStderr()
+	listed := make(map[int]bool, len(prs))
+	for _, pr := range prs {
+		listed[pr.Number] = true
+	}
+
+	fmt.Fprintf(out, "Open PRs in %s:\n", title)
+	shown := rows
+	reader := bufio.NewReader(cmd.InOrStdin())
+	for answered := false; ; answered = true {
+		for _, row := range shown {
+			fmt.Fprintf(out, "  %s\n", row)
+		}
+		fmt.Fprint(out, "PR number to open, text to filter, or Enter to cancel\n> ")
+		line, err := reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return 0, fmt.Errorf("read answer: %w", err)
+		}
+		if !answered && line == "" && errors.Is(err, io.EOF) {
+			fmt.Fprintln(out)
+			return 0, errMissingPRRef
+		}
+		answer := strings.TrimSpace(line)
+		if answer == "" {
+			return 0, errNoPRSelected
+		}
+		if number, ok := github.ParsePRNumber(answer); ok {
+			if listed[number] {
+				return number, nil
+			}
+			fmt.Fprintf(out, "#%d is not an open PR in the list\n", number)
+		} else {
+			var matches []string
+			for _, row := range rows {
+				if fuzzyMatch(row, answer) {
+					matches = append(matches, row)
+				}
+			}
+			if len(matches) == 0 {
+				fmt.Fprintf(out, "No PRs match %q\n", answer)
+			} else {
+				shown = matches
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return 0, errNoPRSelected
+		}
+	}
+}
+
+// fuzzyMatch reports whether the characters of pattern appear in text in
+// order, ignoring case and spaces in pattern.
+func fuzzyMatch(text string, pattern string) bool {
+	text = strings.ToLower(text)
+	for _, r := range strings.ToLower(pattern) {
+		if r == ' ' {
+			continue
+		}
+		index := strings.IndexRune(text, r)
+		if index < 0 {
+			return false
+		}
+		text = text[index+utf8.RuneLen(r):]
+	}
+	return true
+}
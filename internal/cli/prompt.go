@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// isInteractive reports whether the command reads from a terminal.
+func isInteractive(cmd *cobra.Command) bool {
+	return isTerminal(cmd.InOrStdin())
+}
+
+// isTerminal reports whether stream, a command's input or output, is a
+// terminal.
+func isTerminal(stream any) bool {
+	file, ok := stream.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// promptChoice asks question on stderr and returns the selected choice key.
+// An empty answer selects defaultKey.
+func promptChoice(cmd *cobra.Command, question string, choices []promptOption, defaultKey string) (string, error) {
+	out := cmd.ErrOrStderr()
+	fmt.Fprintln(out, question)
+	for _, choice := range choices {
+		marker := " "
+		if choice.Key == defaultKey {
+			marker = "*"
+		}
+		fmt.Fprintf(out, " %s [%s] %s\n", marker, choice.Key, choice.Label)
+	}
+
+	reader := bufio.NewReader(cmd.InOrStdin())
+	for {
+		fmt.Fprint(out, "> ")
+		line, err := reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", fmt.Errorf("read answer: %w", err)
+		}
+		answer := strings.ToLower(strings.TrimSpace(line))
+		if answer == "" {
+			return defaultKey, nil
+		}
+		for _, choice := range choices {
+			if answer == choice.Key {
+				return choice.Key, nil
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return "", fmt.Errorf("invalid answer %q", answer)
+		}
+		fmt.Fprintf(out, "Please answer one of: %s\n", promptKeys(choices))
+	}
+}
+
+type promptOption struct {
+	Key   string
+	Label string
+}
+
+func promptKeys(choices []promptOption) string {
+	keys := make([]string, 0, len(choices))
+	for _, choice := range choices {
+		keys = append(keys, choice.Key)
+	}
+	return strings.Join(keys, ", ")
+}
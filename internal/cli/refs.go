@@ -0,0 +1,199 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BradyPlanden/prt/internal/config"
+	"github.com/BradyPlanden/prt/internal/git"
+	"github.com/BradyPlanden/prt/internal/github"
+	"github.com/BradyPlanden/prt/internal/workspace"
+)
+
+// resolvePRReference turns root command arguments into a PR reference.
+// Accepted forms are a PR URL, "owner/repo#123", "owner/repo 123", and a
+// bare "123" or "#123" when run inside a clone under the projects directory.
+func resolvePRReference(ctx context.Context, cfg config.Config, args []string) (github.PRRef, error) {
+	switch len(args) {
+	case 1:
+		if number, ok := github.ParsePRNumber(args[0]); ok {
+			repo, err := currentRepository(ctx, cfg)
+			if err != nil {
+				return github.PRRef{}, err
+			}
+			return github.PRRef{Host: repo.Host, Owner: repo.Owner, Repo: repo.Name, Number: number}, nil
+		}
+		return github.ParsePRReference(args[0], cfg.GitHubHosts)
+	case 2:
+		if strings.Contains(args[0], "#") || strings.Contains(args[0], "/pull/") {
+			return github.PRRef{}, fmt.Errorf("accepts 1 arg(s) when the first is a full reference, received %d", len(args))
+		}
+		return github.ParsePRReference(args[0]+"#"+strings.TrimPrefix(args[1], "#"), cfg.GitHubHosts)
+	default:
+		return github.PRRef{}, fmt.Errorf("accepts 1 or 2 arg(s), received %d", len(args))
+	}
+}
+
+// collectPRReferences gathers the PRs to open from the arguments and, when
+// from is set, a file ("-" for in) with one reference per line. Each
+// argument is a separate reference, except the two-argument "owner/repo N"
+// form. Blank lines and lines starting with "# " are skipped, and
+// duplicates are dropped.
+func collectPRReferences(ctx context.Context, cfg config.Config, args []string, from string, in io.Reader) ([]github.PRRef, error) {
+	var refs []github.PRRef
+	if isRepoNumberPair(args) {
+		ref, err := resolvePRReference(ctx, cfg, args)
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, ref)
+	} else {
+		for _, arg := range args {
+			ref, err := resolvePRReference(ctx, cfg, []string{arg})
+			if err != nil {
+				return nil, err
+			}
+			refs = append(refs, ref)
+		}
+	}
+
+	if from != "" {
+		fromRefs, err := readPRReferences(ctx, cfg, from, in)
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, fromRefs...)
+	}
+	if len(refs) == 0 {
+		return nil, fmt.Errorf("no PR references given")
+	}
+
+	seen := make(map[string]bool, len(refs))
+	unique := refs[:0]
+	for _, ref := range refs {
+		key := ref.URL() + "@" + ref.Commit
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, ref)
+	}
+	return unique, nil
+}
+
+// isRepoNumberPair reports whether args is the "owner/repo N" form.
+func isRepoNumberPair(args []string) bool {
+	if len(args) != 2 {
+		return false
+	}
+	if _, ok := github.ParsePRNumber(args[0]); ok {
+		return false
+	}
+	if strings.Contains(args[0], "#") || strings.Contains(args[0], "/pull/") {
+		return false
+	}
+	_, ok := github.ParsePRNumber(args[1])
+	return ok
+}
+
+func readPRReferences(ctx context.Context, cfg config.Config, from string, in io.Reader) ([]github.PRRef, error) {
+	name := from
+	if from == "-" {
+		name = "stdin"
+	} else {
+		file, err := os.Open(from)
+		if err != nil {
+			return nil, fmt.Errorf("read PR references: %w", err)
+		}
+		defer file.Close()
+		in = file
+	}
+
+	var refs []github.PRRef
+	scanner := bufio.NewScanner(in)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || text == "#" || strings.HasPrefix(text, "# ") {
+			continue
+		}
+		ref, err := resolvePRReference(ctx, cfg, strings.Fields(text))
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", name, line, err)
+		}
+		refs = append(refs, ref)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read PR references from %s: %w", name, err)
+	}
+	return refs, nil
+}
+
+// refLabel returns the short "owner/repo#N" form of ref.
+func refLabel(ref github.PRRef) string {
+	return fmt.Sprintf("%s/%s#%d", ref.Owner, ref.Repo, ref.Number)
+}
+
+// currentRepository infers the GitHub repository from the origin remote of
+// the current directory, which must be inside the projects or temp directory.
+func currentRepository(ctx context.Context, cfg config.Config) (github.Repository, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return github.Repository{}, fmt.Errorf("resolve current directory: %w", err)
+	}
+	if !isWithin(cwd, cfg.ProjectsDir) && !isWithin(cwd, cfg.TempDir) {
+		return github.Repository{}, fmt.Errorf("bare PR numbers require running inside a clone under %s", cfg.ProjectsDir)
+	}
+
+	gitClient := git.NewClient(git.ClientOptions{})
+	origin, err := gitClient.OriginURL(ctx, cwd)
+	if err != nil {
+		return github.Repository{}, fmt.Errorf("infer repository from origin: %w", err)
+	}
+	repo, ok := workspace.RepoFromRemote(origin)
+	if !ok {
+		return github.Repository{}, fmt.Errorf("could not parse repository from origin %q", origin)
+	}
+	if repo.Host == "" {
+		// SSH host aliases cannot be resolved; assume github.com.
+		repo.Host = github.DefaultHost
+	}
+	if repo.Host != github.DefaultHost && !containsHost(cfg.GitHubHosts, repo.Host) {
+		return github.Repository{}, fmt.Errorf("origin host %s is not github.com or a configured github_hosts entry", repo.Host)
+	}
+	return repo, nil
+}
+
+func isWithin(path string, dir string) bool {
+	if dir == "" {
+		return false
+	}
+	path = evalSymlinks(path)
+	dir = evalSymlinks(dir)
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func evalSymlinks(path string) string {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	return resolved
+}
+
+func containsHost(hosts []string, host string) bool {
+	for _, candidate := range hosts {
+		if strings.EqualFold(candidate, host) {
+			return true
+		}
+	}
+	return false
+}
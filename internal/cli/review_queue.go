@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/BradyPlanden/prt/internal/github"
+	"github.com/spf13/cobra"
+)
+
+type reviewQueueOptions struct {
+	ReviewRequested string
+	Team            string
+	Author          string
+	Labels          []string
+	Repo            string
+	Search          string
+	Host            string
+	Limit           int
+	DryRun          bool
+}
+
+func newReviewQueueCommand(rootOpts *rootOptions) *cobra.Command {
+	opts := &reviewQueueOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "review-queue",
+		Short: "Open every open PR awaiting your review, or matching a search",
+		Long: "Find open PRs with GitHub search and open a worktree for each, like passing\n" +
+			"them all to prt. Without filters it finds PRs whose review is requested\n" +
+			"from you.",
+		Example: "" +
+			"  prt review-queue\n" +
+			"  prt review-queue --team octo/reviewers --temp\n" +
+			"  prt review-queue --repo octo/repo --label needs-review\n" +
+			"  prt review-queue --search 'author:hubot draft:false' --dry-run",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runReviewQueue(cmd, rootOpts, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.ReviewRequested, "review-requested", "", "PRs whose review is requested from this user (@me for you)")
+	cmd.Flags().StringVar(&opts.Team, "team", "", "PRs whose review is requested from this ORG/TEAM")
+	cmd.Flags().StringVar(&opts.Author, "author", "", "PRs opened by this user")
+	cmd.Flags().StringArrayVar(&opts.Labels, "label", nil, "PRs with this label (repeatable)")
+	cmd.Flags().StringVar(&opts.Repo, "repo", "", "Only PRs in OWNER/REPO")
+	cmd.Flags().StringVar(&opts.Search, "search", "", "GitHub search query added to the filters")
+	cmd.Flags().StringVar(&opts.Host, "host", "", "GitHub host to search (default github.com)")
+	cmd.Flags().IntVar(&opts.Limit, "limit", github.DefaultSearchLimit, "Open at most this many PRs")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Print the matching PR URLs instead of opening them")
+	cmd.Flags().BoolVarP(&rootOpts.Temp, "temp", "t", false, "Use temporary worktrees")
+	cmd.Flags().BoolVar(&rootOpts.NoTab, "no-tab", false, "Print paths instead of opening tabs")
+	cmd.Flags().IntVarP(&rootOpts.Jobs, "jobs", "j", 4, "Resolve up to this many PRs at once")
+
+	return cmd
+}
+
+func runReviewQueue(cmd *cobra.Command, rootOpts *rootOptions, opts *reviewQueueOptions) error {
+	cfg, err := loadConfig(rootOpts)
+	if err != nil {
+		return err
+	}
+	session, err := newOpenSession(cmd, cfg, rootOpts)
+	if err != nil {
+		return err
+	}
+
+	search := github.SearchOptions{
+		Host:            opts.Host,
+		ReviewRequested: opts.ReviewRequested,
+		Team:            opts.Team,
+		Author:          opts.Author,
+		Labels:          opts.Labels,
+		Repo:            opts.Repo,
+		Query:           opts.Search,
+		Limit:           opts.Limit,
+	}
+	if search.ReviewRequested == "" && search.Team == "" && search.Author == "" && len(search.Labels) == 0 && search.Query == "" {
+		search.ReviewRequested = "@me"
+	}
+
+	ctx, cancel := withDefaultTimeout(cmd.Context())
+	defer cancel()
+	refs, err := session.github.SearchPRs(ctx, search)
+	if err != nil {
+		return err
+	}
+	if len(refs) == 0 {
+		fmt.Fprintln(cmd.ErrOrStderr(), "No matching PRs")
+		return nil
+	}
+
+	if opts.DryRun {
+		for _, ref := range refs {
+			fmt.Fprintln(cmd.OutOrStdout(), ref.URL())
+		}
+		return nil
+	}
+	return session.openBatch(refs)
+}
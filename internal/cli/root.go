@@ -9,14 +9,28 @@ import (
 )
 
 type rootOptions struct {
-	Temp     bool
-	Projects string
-	NoTab    bool
-	Verbose  bool
-	Terminal string
-	TempDir  string
-	TempTTL  string
-	Config   string
+	Temp          bool
+	Projects      string
+	NoTab         bool
+	Verbose       bool
+	Terminal      string
+	TempDir       string
+	TempTTL       string
+	Config        string
+	CommitMode    string
+	PersistentTTL string
+	Force         bool
+	Update        bool
+	OpenWith      string
+	Editor        bool
+	EditorCmd     string
+	FirstChanged  bool
+	NoSubmodules  bool
+	Depth         int
+	Filter        string
+	SingleBranch  bool
+	Jobs          int
+	From          string
 }
 
 // Execute runs the root prt command.
@@ -32,31 +46,47 @@ func newRootCommand(version string) *cobra.Command {
 	}
 
 	cmd := &cobra.Command{
-		Use:   "prt <PR-URL>",
-		Short: "Open a GitHub PR in a new terminal tab",
+		Use:   "prt [<PR-URL | OWNER/REPO#N | OWNER/REPO N | N>...]",
+		Short: "Open GitHub PRs in new terminal tabs",
 		Example: "" +
 			"  prt https://github.com/OWNER/REPO/pull/123\n" +
+			"  prt OWNER/REPO#123\n" +
+			"  prt 123 (inside a clone under the projects directory)\n" +
+			"  prt (inside a clone: pick from its open PRs)\n" +
 			"  prt https://github.com/OWNER/REPO/pull/123 --temp\n" +
 			"  prt https://github.com/OWNER/REPO/pull/123 --no-tab\n" +
-			"  prt clean --dry-run",
-		Args: func(_ *cobra.Command, args []string) error {
-			if len(args) == 0 {
-				return fmt.Errorf("missing PR URL argument (run 'prt --help')")
-			}
-			if len(args) > 1 {
-				return fmt.Errorf("accepts 1 arg(s), received %d", len(args))
-			}
-			return nil
-		},
+			"  prt OWNER/REPO#123 --editor-cmd code --first-changed\n" +
+			"  prt OWNER/REPO#123 --open-with both\n" +
+			"  prt OWNER/REPO#123 OWNER/REPO#124 OTHER/REPO#7 --jobs 4\n" +
+			"  prt --from prs.txt\n" +
+			"  prt list\n" +
+			"  prt review-queue --temp\n" +
+			"  prt status\n" +
+			"  prt clean --dry-run\n" +
+			"  prt cache list",
+		Args: cobra.ArbitraryArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runOpen(cmd, opts, args[0])
+			return runOpen(cmd, opts, args)
 		},
 	}
 
 	cmd.Flags().BoolVarP(&opts.Temp, "temp", "t", false, "Use a temporary worktree")
 	cmd.Flags().StringVar(&opts.Projects, "dir", "", "Override projects directory")
 	cmd.Flags().BoolVar(&opts.NoTab, "no-tab", false, "Print path instead of opening a tab")
-	cmd.Flags().StringVar(&opts.Terminal, "terminal", "", "Override terminal (auto|iterm2|terminal)")
+	cmd.Flags().StringVar(&opts.Terminal, "terminal", "", "Override terminal (auto|iterm2|terminal|gnome-terminal|konsole|kitty|wezterm|alacritty|tmux|tmux-session|zellij|command)")
+	cmd.Flags().StringVar(&opts.OpenWith, "open-with", "", "Override open_with (terminal|editor|both)")
+	cmd.Flags().BoolVar(&opts.Editor, "editor", false, "Open the worktree in an editor instead of a terminal tab")
+	cmd.Flags().StringVar(&opts.EditorCmd, "editor-cmd", "", "Editor to open, implying --editor (code|cursor|idea|zed|nvim|...; default editor, $VISUAL, $EDITOR)")
+	cmd.Flags().BoolVar(&opts.FirstChanged, "first-changed", false, "Open the PR's first changed file in the editor")
+	cmd.Flags().BoolVar(&opts.NoSubmodules, "no-submodules", false, "Skip submodule initialization")
+	cmd.Flags().IntVar(&opts.Depth, "depth", 0, "Make first-time clones shallow with this many commits")
+	cmd.Flags().StringVar(&opts.Filter, "filter", "", "Partial clone filter for first-time clones (blob:none|tree:0)")
+	cmd.Flags().BoolVar(&opts.SingleBranch, "single-branch", false, "Clone only the default branch the first time")
+	cmd.Flags().IntVarP(&opts.Jobs, "jobs", "j", 4, "Resolve up to this many PRs at once when opening several")
+	cmd.Flags().StringVar(&opts.From, "from", "", "Read PR references from a file, one per line (- for stdin)")
+	cmd.Flags().BoolVar(&opts.Update, "update", false, "Fast-forward a reused worktree to the latest PR head")
+	cmd.Flags().BoolVar(&opts.Force, "force", false, "Let on_force_push: reset discard uncommitted changes and local commits")
+	cmd.Flags().StringVar(&opts.CommitMode, "commit-mode", "", "For /commits/<sha> links: head|detach|branch (prompts when interactive)")
 	cmd.PersistentFlags().BoolVar(&opts.Verbose, "verbose", false, "Enable verbose logging")
 	cmd.PersistentFlags().StringVar(&opts.TempDir, "temp-dir", "", "Override temp directory")
 	cmd.PersistentFlags().StringVar(&opts.TempTTL, "temp-ttl", "", "Override temp cleanup TTL (e.g. 24h)")
@@ -67,6 +97,10 @@ func newRootCommand(version string) *cobra.Command {
 
 	cmd.AddCommand(newVersionCommand(version))
 	cmd.AddCommand(newCleanCommand(opts))
+	cmd.AddCommand(newListCommand(opts))
+	cmd.AddCommand(newStatusCommand(opts))
+	cmd.AddCommand(newCacheCommand(opts))
+	cmd.AddCommand(newReviewQueueCommand(opts))
 
 	cmd.SetOut(os.Stdout)
 	cmd.SetErr(os.Stderr)
@@ -92,12 +126,18 @@ func newVersionCommand(version string) *cobra.Command {
 
 func loadConfig(opts *rootOptions) (config.Config, error) {
 	overrides := config.Overrides{
-		ProjectsDir: opts.Projects,
-		TempDir:     opts.TempDir,
-		Terminal:    opts.Terminal,
-		TempTTL:     opts.TempTTL,
-		Verbose:     opts.Verbose,
-		ConfigPath:  opts.Config,
+		ProjectsDir:   opts.Projects,
+		TempDir:       opts.TempDir,
+		Terminal:      opts.Terminal,
+		TempTTL:       opts.TempTTL,
+		PersistentTTL: opts.PersistentTTL,
+		Verbose:       opts.Verbose,
+		ConfigPath:    opts.Config,
+		OpenWith:      opts.OpenWith,
+		Editor:        opts.EditorCmd,
+		CloneDepth:    opts.Depth,
+		CloneFilter:   opts.Filter,
+		SingleBranch:  opts.SingleBranch,
 	}
 	return config.Load(overrides)
 }
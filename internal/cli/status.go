@@ -0,0 +1,180 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/BradyPlanden/prt/internal/git"
+	"github.com/BradyPlanden/prt/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+type statusOptions struct {
+	JSON    bool
+	NoFetch bool
+}
+
+type statusEntry struct {
+	Path        string   `json:"path"`
+	Branch      string   `json:"branch,omitempty"`
+	Upstream    string   `json:"upstream,omitempty"`
+	Ahead       int      `json:"ahead"`
+	Behind      int      `json:"behind"`
+	ForcePushed bool     `json:"force_pushed"`
+	CheckoutSHA string   `json:"checkout_sha,omitempty"`
+	Base        string   `json:"base,omitempty"`
+	BaseBehind  int      `json:"base_behind"`
+	MergeBase   string   `json:"merge_base,omitempty"`
+	DirtyFiles  []string `json:"dirty_files"`
+}
+
+func newStatusCommand(rootOpts *rootOptions) *cobra.Command {
+	opts := &statusOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "status [PR-URL | OWNER/REPO#N | OWNER/REPO N | N]",
+		Short: "Show how a PR worktree differs from the remote PR",
+		Example: "" +
+			"  prt status (inside a PR worktree)\n" +
+			"  prt status OWNER/REPO#123\n" +
+			"  prt status 123 --no-fetch",
+		Args: cobra.MaximumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runStatus(cmd, rootOpts, opts, args)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.JSON, "json", false, "Print status as JSON")
+	cmd.Flags().BoolVar(&opts.NoFetch, "no-fetch", false, "Compare against already-fetched refs without contacting remotes")
+
+	return cmd
+}
+
+func runStatus(cmd *cobra.Command, rootOpts *rootOptions, opts *statusOptions, args []string) error {
+	cfg, err := loadConfig(rootOpts)
+	if err != nil {
+		return err
+	}
+
+	logger := log.New(cmd.ErrOrStderr(), "", 0)
+	gitClient := git.NewClient(git.ClientOptions{
+		Verbose: cfg.Verbose,
+		Logger:  logger,
+	})
+	resolver := workspace.NewResolver(gitClient, workspace.ResolverOptions{
+		Logger: logger,
+	})
+
+	ctx, cancel := withDefaultTimeout(cmd.Context())
+	defer cancel()
+
+	var paths []string
+	if len(args) == 0 {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("resolve current directory: %w", err)
+		}
+		top, err := gitClient.TopLevel(ctx, cwd)
+		if err != nil {
+			return errors.New("not inside a PR worktree; pass a PR reference")
+		}
+		paths = []string{top}
+	} else {
+		ref, err := resolvePRReference(ctx, cfg, args)
+		if err != nil {
+			return err
+		}
+		infos, err := resolver.List(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		for _, info := range infos {
+			if info.Missing || info.Number != ref.Number {
+				continue
+			}
+			if !strings.EqualFold(info.Repo.Owner, ref.Owner) || !strings.EqualFold(info.Repo.Name, ref.Repo) {
+				continue
+			}
+			paths = append(paths, info.Path)
+		}
+		if len(paths) == 0 {
+			return fmt.Errorf("no prt worktree found for %s", ref.URL())
+		}
+	}
+
+	entries := make([]statusEntry, 0, len(paths))
+	for _, path := range paths {
+		status, err := resolver.Status(ctx, path, workspace.StatusOptions{Fetch: !opts.NoFetch})
+		if err != nil {
+			return err
+		}
+		for _, warning := range status.Warnings {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %s\n", warning)
+		}
+		entries = append(entries, statusEntry{
+			Path:        status.Path,
+			Branch:      status.Branch,
+			Upstream:    status.Upstream,
+			Ahead:       status.Ahead,
+			Behind:      status.Behind,
+			ForcePushed: status.ForcePushed,
+			CheckoutSHA: status.CheckoutSHA,
+			Base:        status.Base,
+			BaseBehind:  status.BaseBehind,
+			MergeBase:   status.MergeBase,
+			DirtyFiles:  append([]string{}, status.DirtyFiles...),
+		})
+	}
+
+	if opts.JSON {
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(entries)
+	}
+
+	out := cmd.OutOrStdout()
+	for i, entry := range entries {
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		fmt.Fprintln(out, entry.Path)
+		fmt.Fprintf(out, "  Branch:   %s\n", valueOrDash(entry.Branch))
+		if entry.Upstream == "" {
+			fmt.Fprintln(out, "  Upstream: -")
+		} else {
+			fmt.Fprintf(out, "  Upstream: %s (%s)\n", entry.Upstream, aheadBehindLabel(entry.Ahead, entry.Behind))
+		}
+		if entry.ForcePushed {
+			fmt.Fprintf(out, "  Force-pushed since checkout (was %s)\n", workspace.ShortSHA(entry.CheckoutSHA))
+		}
+		if entry.Base != "" {
+			fmt.Fprintf(out, "  Base:     %s (%d behind, merge-base %s)\n", entry.Base, entry.BaseBehind, valueOrDash(workspace.ShortSHA(entry.MergeBase)))
+		}
+		if len(entry.DirtyFiles) == 0 {
+			fmt.Fprintln(out, "  Dirty:    no")
+			continue
+		}
+		fmt.Fprintf(out, "  Dirty:    %d file(s)\n", len(entry.DirtyFiles))
+		for _, file := range entry.DirtyFiles {
+			fmt.Fprintf(out, "    %s\n", file)
+		}
+	}
+	return nil
+}
+
+func aheadBehindLabel(ahead int, behind int) string {
+	switch {
+	case ahead == 0 && behind == 0:
+		return "up to date"
+	case behind == 0:
+		return fmt.Sprintf("%d ahead", ahead)
+	case ahead == 0:
+		return fmt.Sprintf("%d behind", behind)
+	default:
+		return fmt.Sprintf("diverged: %d ahead, %d behind", ahead, behind)
+	}
+}
@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/BradyPlanden/prt/internal/github"
 	"gopkg.in/yaml.v3"
 )
 
@@ -17,42 +19,178 @@ const (
 	defaultTempTTL     = 24 * time.Hour
 	defaultTerminal    = "auto"
 	defaultConfigPath  = "~/.config/prt/config.yaml"
+	defaultBackend     = "auto"
+	defaultOnForcePush = "warn"
+	defaultOpenWith    = "terminal"
+	defaultHookTimeout = 5 * time.Minute
 )
 
 // Config stores runtime settings for repository and terminal behavior.
 type Config struct {
-	ProjectsDir string
-	TempDir     string
-	TempTTL     time.Duration
-	Terminal    string
-	Verbose     bool
+	ProjectsDir        string
+	TempDir            string
+	TempTTL            time.Duration
+	PersistentTTL      time.Duration
+	Terminal           string
+	Verbose            bool
+	GitHubBackend      string
+	GitHubHosts        []string
+	OnForcePush        string
+	UpdateOnReuse      bool
+	OpenWith           string
+	Editor             string
+	EditorFirstChanged bool
+	TerminalCommand    []string
+	TerminalShell      bool
+	Hooks              Hooks
+	TrustedRepos       []string
+	CopyFiles          map[string]CopyRule
+	Submodules         Submodules
+	RepoSubmodules     map[string]Submodules
+	Clone              Clone
+	CacheDir           string
+}
+
+// Clone configures how repositories are cloned the first time they are
+// used. Zero values clone the full history of every branch.
+type Clone struct {
+	Depth int
+	// Filter is a partial clone filter: blob:none, tree:0, or
+	// blob:limit=<size>.
+	Filter       string
+	SingleBranch bool
+}
+
+// CopyRule lists glob patterns, relative to the repository root, of files
+// or directories to copy or symlink from the main checkout into new
+// worktrees. Config.CopyFiles maps owner/repo globs to rules.
+type CopyRule struct {
+	Copy    []string
+	Symlink []string
+}
+
+// Hooks maps owner/repo glob patterns to shell commands run in a worktree
+// after it is created or reused.
+type Hooks struct {
+	PostCreate map[string][]string
+	PostReuse  map[string][]string
+	// Timeout bounds each hook command.
+	Timeout time.Duration
 }
 
 // Overrides contains CLI-supplied values that override file and env config.
 type Overrides struct {
-	ProjectsDir string
-	TempDir     string
-	TempTTL     string
-	Terminal    string
-	Verbose     bool
-	ConfigPath  string
+	ProjectsDir   string
+	TempDir       string
+	TempTTL       string
+	PersistentTTL string
+	Terminal      string
+	Verbose       bool
+	ConfigPath    string
+	OpenWith      string
+	Editor        string
+	CloneDepth    int
+	CloneFilter   string
+	SingleBranch  bool
 }
 
 type fileConfig struct {
-	ProjectsDir string `yaml:"projects_dir"`
-	TempDir     string `yaml:"temp_dir"`
-	TempTTL     string `yaml:"temp_ttl"`
-	Terminal    string `yaml:"terminal"`
+	ProjectsDir        string                  `yaml:"projects_dir"`
+	TempDir            string                  `yaml:"temp_dir"`
+	TempTTL            string                  `yaml:"temp_ttl"`
+	PersistentTTL      string                  `yaml:"persistent_ttl"`
+	Terminal           string                  `yaml:"terminal"`
+	GitHubBackend      string                  `yaml:"github_backend"`
+	GitHubHosts        []string                `yaml:"github_hosts"`
+	OnForcePush        string                  `yaml:"on_force_push"`
+	UpdateOnReuse      *bool                   `yaml:"update_on_reuse"`
+	OpenWith           string                  `yaml:"open_with"`
+	Editor             string                  `yaml:"editor"`
+	EditorFirstChanged bool                    `yaml:"editor_first_changed"`
+	TerminalCommand    commandTemplate         `yaml:"terminal_command"`
+	Hooks              fileHooks               `yaml:"hooks"`
+	TrustedRepos       []string                `yaml:"trusted_repos"`
+	CopyFiles          map[string]fileCopyRule `yaml:"copy_files"`
+	Submodules         fileGlobalSubmodules    `yaml:"submodules"`
+	Clone              fileClone               `yaml:"clone"`
+	CacheDir           string                  `yaml:"cache_dir"`
+}
+
+type fileClone struct {
+	Depth        int    `yaml:"depth"`
+	Filter       string `yaml:"filter"`
+	SingleBranch bool   `yaml:"single_branch"`
+}
+
+// fileCopyRule accepts a list of patterns to copy, or a mapping with copy
+// and symlink lists.
+type fileCopyRule struct {
+	Copy    stringList `yaml:"copy"`
+	Symlink stringList `yaml:"symlink"`
+}
+
+func (r *fileCopyRule) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.MappingNode {
+		return value.Decode(&r.Copy)
+	}
+	type plain fileCopyRule
+	return value.Decode((*plain)(r))
+}
+
+type fileHooks struct {
+	Timeout    string                `yaml:"timeout"`
+	PostCreate map[string]stringList `yaml:"post_create"`
+	PostReuse  map[string]stringList `yaml:"post_reuse"`
+}
+
+// commandTemplate accepts a single YAML string, a shell command line, or a
+// list of arguments run without a shell.
+type commandTemplate struct {
+	Args  []string
+	Shell bool
+}
+
+func (c *commandTemplate) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*c = commandTemplate{Args: []string{value.Value}, Shell: true}
+		return nil
+	}
+	var args []string
+	if err := value.Decode(&args); err != nil {
+		return err
+	}
+	*c = commandTemplate{Args: args}
+	return nil
+}
+
+// stringList accepts either a single YAML string or a list of strings.
+type stringList []string
+
+func (l *stringList) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*l = stringList{value.Value}
+		return nil
+	}
+	var values []string
+	if err := value.Decode(&values); err != nil {
+		return err
+	}
+	*l = values
+	return nil
 }
 
 // Load reads configuration from disk, environment, and explicit overrides.
 func Load(overrides Overrides) (Config, error) {
 	cfg := Config{
-		ProjectsDir: defaultProjectsDir,
-		TempDir:     defaultTempDir,
-		TempTTL:     defaultTempTTL,
-		Terminal:    defaultTerminal,
-		Verbose:     false,
+		ProjectsDir:   defaultProjectsDir,
+		TempDir:       defaultTempDir,
+		TempTTL:       defaultTempTTL,
+		Terminal:      defaultTerminal,
+		Verbose:       false,
+		GitHubBackend: defaultBackend,
+		OnForcePush:   defaultOnForcePush,
+		OpenWith:      defaultOpenWith,
+		Hooks:         Hooks{Timeout: defaultHookTimeout},
 	}
 
 	configPath := overrides.ConfigPath
@@ -79,6 +217,25 @@ func Load(overrides Overrides) (Config, error) {
 	if err := expandConfigPaths(&cfg); err != nil {
 		return Config{}, err
 	}
+	if err := validateBackend(cfg.GitHubBackend); err != nil {
+		return Config{}, err
+	}
+	cfg.OnForcePush = strings.ToLower(strings.TrimSpace(cfg.OnForcePush))
+	if err := validateOnForcePush(cfg.OnForcePush); err != nil {
+		return Config{}, err
+	}
+	cfg.OpenWith = strings.ToLower(strings.TrimSpace(cfg.OpenWith))
+	if err := validateOpenWith(cfg.OpenWith); err != nil {
+		return Config{}, err
+	}
+	cfg.Clone.Filter = strings.TrimSpace(cfg.Clone.Filter)
+	if err := validateClone(cfg.Clone); err != nil {
+		return Config{}, err
+	}
+	cfg.Submodules.Policy = strings.ToLower(strings.TrimSpace(cfg.Submodules.Policy))
+	if err := validateSubmodulePolicy(cfg.Submodules.Policy); err != nil {
+		return Config{}, err
+	}
 
 	return cfg, nil
 }
@@ -110,9 +267,90 @@ func applyFileConfig(cfg *Config, path string) error {
 		}
 		cfg.TempTTL = parsed
 	}
+	if fileCfg.PersistentTTL != "" {
+		parsed, err := time.ParseDuration(fileCfg.PersistentTTL)
+		if err != nil {
+			return fmt.Errorf("invalid persistent_ttl: %w", err)
+		}
+		cfg.PersistentTTL = parsed
+	}
 	if fileCfg.Terminal != "" {
 		cfg.Terminal = fileCfg.Terminal
 	}
+	if fileCfg.GitHubBackend != "" {
+		cfg.GitHubBackend = fileCfg.GitHubBackend
+	}
+	if len(fileCfg.GitHubHosts) > 0 {
+		cfg.GitHubHosts = normalizeHosts(fileCfg.GitHubHosts)
+	}
+	if fileCfg.OnForcePush != "" {
+		cfg.OnForcePush = fileCfg.OnForcePush
+	}
+	if fileCfg.UpdateOnReuse != nil {
+		cfg.UpdateOnReuse = *fileCfg.UpdateOnReuse
+	}
+	if fileCfg.OpenWith != "" {
+		cfg.OpenWith = fileCfg.OpenWith
+	}
+	if fileCfg.Editor != "" {
+		cfg.Editor = fileCfg.Editor
+	}
+	if fileCfg.EditorFirstChanged {
+		cfg.EditorFirstChanged = true
+	}
+	if len(fileCfg.TerminalCommand.Args) > 0 {
+		cfg.TerminalCommand = fileCfg.TerminalCommand.Args
+		cfg.TerminalShell = fileCfg.TerminalCommand.Shell
+	}
+	if fileCfg.Hooks.Timeout != "" {
+		parsed, err := time.ParseDuration(fileCfg.Hooks.Timeout)
+		if err != nil {
+			return fmt.Errorf("invalid hooks.timeout: %w", err)
+		}
+		cfg.Hooks.Timeout = parsed
+	}
+	if len(fileCfg.TrustedRepos) > 0 {
+		cfg.TrustedRepos = fileCfg.TrustedRepos
+	}
+	if len(fileCfg.CopyFiles) > 0 {
+		cfg.CopyFiles = make(map[string]CopyRule, len(fileCfg.CopyFiles))
+		for pattern, rule := range fileCfg.CopyFiles {
+			copyRule, err := cleanCopyRule(rule)
+			if err != nil {
+				return fmt.Errorf("invalid copy_files for %s: %w", pattern, err)
+			}
+			cfg.CopyFiles[pattern] = copyRule
+		}
+	}
+	if fileCfg.CacheDir != "" {
+		cfg.CacheDir = fileCfg.CacheDir
+	}
+	if fileCfg.Clone.Depth != 0 {
+		cfg.Clone.Depth = fileCfg.Clone.Depth
+	}
+	if fileCfg.Clone.Filter != "" {
+		cfg.Clone.Filter = fileCfg.Clone.Filter
+	}
+	if fileCfg.Clone.SingleBranch {
+		cfg.Clone.SingleBranch = true
+	}
+	submodules, err := fileCfg.Submodules.parse()
+	if err != nil {
+		return fmt.Errorf("invalid submodules: %w", err)
+	}
+	cfg.Submodules = submodules
+	if len(fileCfg.Submodules.Repos) > 0 {
+		cfg.RepoSubmodules = make(map[string]Submodules, len(fileCfg.Submodules.Repos))
+		for pattern, entry := range fileCfg.Submodules.Repos {
+			repoSubmodules, err := entry.parse()
+			if err != nil {
+				return fmt.Errorf("invalid submodules for %s: %w", pattern, err)
+			}
+			cfg.RepoSubmodules[pattern] = repoSubmodules
+		}
+	}
+	cfg.Hooks.PostCreate = hookCommands(fileCfg.Hooks.PostCreate)
+	cfg.Hooks.PostReuse = hookCommands(fileCfg.Hooks.PostReuse)
 
 	return nil
 }
@@ -131,12 +369,66 @@ func applyEnv(cfg *Config) error {
 		}
 		cfg.TempTTL = parsed
 	}
+	if value := os.Getenv("PRT_PERSISTENT_TTL"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid PRT_PERSISTENT_TTL: %w", err)
+		}
+		cfg.PersistentTTL = parsed
+	}
 	if value := os.Getenv("PRT_TERMINAL"); value != "" {
 		cfg.Terminal = value
 	}
 	if value := os.Getenv("PRT_VERBOSE"); value != "" {
 		cfg.Verbose = parseBool(value)
 	}
+	if value := os.Getenv("PRT_GITHUB_BACKEND"); value != "" {
+		cfg.GitHubBackend = value
+	}
+	if value := os.Getenv("PRT_GITHUB_HOSTS"); value != "" {
+		cfg.GitHubHosts = normalizeHosts(strings.Split(value, ","))
+	}
+	if value := os.Getenv("PRT_ON_FORCE_PUSH"); value != "" {
+		cfg.OnForcePush = value
+	}
+	if value := os.Getenv("PRT_UPDATE_ON_REUSE"); value != "" {
+		cfg.UpdateOnReuse = parseBool(value)
+	}
+	if value := os.Getenv("PRT_OPEN_WITH"); value != "" {
+		cfg.OpenWith = value
+	}
+	if value := os.Getenv("PRT_EDITOR"); value != "" {
+		cfg.Editor = value
+	}
+	if value := os.Getenv("PRT_EDITOR_FIRST_CHANGED"); value != "" {
+		cfg.EditorFirstChanged = parseBool(value)
+	}
+	if value := os.Getenv("PRT_TRUSTED_REPOS"); value != "" {
+		cfg.TrustedRepos = splitList(value)
+	}
+	if value := os.Getenv("PRT_TERMINAL_COMMAND"); value != "" {
+		cfg.TerminalCommand = []string{value}
+		cfg.TerminalShell = true
+	}
+	if value := os.Getenv("PRT_SUBMODULES"); value != "" {
+		cfg.Submodules.Policy = value
+	}
+	if value := os.Getenv("PRT_CACHE_DIR"); value != "" {
+		cfg.CacheDir = value
+	}
+	if value := os.Getenv("PRT_CLONE_DEPTH"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid PRT_CLONE_DEPTH: %w", err)
+		}
+		cfg.Clone.Depth = parsed
+	}
+	if value := os.Getenv("PRT_CLONE_FILTER"); value != "" {
+		cfg.Clone.Filter = value
+	}
+	if value := os.Getenv("PRT_CLONE_SINGLE_BRANCH"); value != "" {
+		cfg.Clone.SingleBranch = parseBool(value)
+	}
 	return nil
 }
 
@@ -154,12 +446,34 @@ func applyOverrides(cfg *Config, overrides Overrides) error {
 		}
 		cfg.TempTTL = parsed
 	}
+	if overrides.PersistentTTL != "" {
+		parsed, err := time.ParseDuration(overrides.PersistentTTL)
+		if err != nil {
+			return fmt.Errorf("invalid persistent_ttl override: %w", err)
+		}
+		cfg.PersistentTTL = parsed
+	}
 	if overrides.Terminal != "" {
 		cfg.Terminal = overrides.Terminal
 	}
 	if overrides.Verbose {
 		cfg.Verbose = true
 	}
+	if overrides.OpenWith != "" {
+		cfg.OpenWith = overrides.OpenWith
+	}
+	if overrides.Editor != "" {
+		cfg.Editor = overrides.Editor
+	}
+	if overrides.CloneDepth != 0 {
+		cfg.Clone.Depth = overrides.CloneDepth
+	}
+	if overrides.CloneFilter != "" {
+		cfg.Clone.Filter = overrides.CloneFilter
+	}
+	if overrides.SingleBranch {
+		cfg.Clone.SingleBranch = true
+	}
 
 	return nil
 }
@@ -174,9 +488,106 @@ func expandConfigPaths(cfg *Config) error {
 	if err != nil {
 		return err
 	}
+	cfg.CacheDir, err = expandPath(cfg.CacheDir)
+	if err != nil {
+		return err
+	}
 	return nil
 }
 
+func validateBackend(value string) error {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "gh", "api", "auto":
+		return nil
+	default:
+		return fmt.Errorf("invalid github_backend %q (expected gh, api, or auto)", value)
+	}
+}
+
+func validateOnForcePush(value string) error {
+	switch value {
+	case "warn", "reset", "prompt":
+		return nil
+	default:
+		return fmt.Errorf("invalid on_force_push %q (expected warn, reset, or prompt)", value)
+	}
+}
+
+func validateClone(clone Clone) error {
+	if clone.Depth < 0 {
+		return fmt.Errorf("invalid clone.depth %d (expected a positive number)", clone.Depth)
+	}
+	switch {
+	case clone.Filter == "", clone.Filter == "blob:none", clone.Filter == "tree:0":
+		return nil
+	case strings.HasPrefix(clone.Filter, "blob:limit=") && clone.Filter != "blob:limit=":
+		return nil
+	default:
+		return fmt.Errorf("invalid clone.filter %q (expected blob:none, tree:0, or blob:limit=<size>)", clone.Filter)
+	}
+}
+
+func cleanCopyRule(rule fileCopyRule) (CopyRule, error) {
+	var cleaned CopyRule
+	for _, pattern := range rule.Copy {
+		value, err := cleanRelativePath(pattern)
+		if err != nil {
+			return CopyRule{}, err
+		}
+		cleaned.Copy = append(cleaned.Copy, value)
+	}
+	for _, pattern := range rule.Symlink {
+		value, err := cleanRelativePath(pattern)
+		if err != nil {
+			return CopyRule{}, err
+		}
+		cleaned.Symlink = append(cleaned.Symlink, value)
+	}
+	return cleaned, nil
+}
+
+func hookCommands(entries map[string]stringList) map[string][]string {
+	if len(entries) == 0 {
+		return nil
+	}
+	commands := make(map[string][]string, len(entries))
+	for pattern, list := range entries {
+		commands[pattern] = list
+	}
+	return commands
+}
+
+func validateOpenWith(value string) error {
+	switch value {
+	case "terminal", "editor", "both":
+		return nil
+	default:
+		return fmt.Errorf("invalid open_with %q (expected terminal, editor, or both)", value)
+	}
+}
+
+// splitList splits a comma-separated value, trimming entries and dropping
+// empty ones.
+func splitList(value string) []string {
+	var entries []string
+	for entry := range strings.SplitSeq(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+func normalizeHosts(values []string) []string {
+	var hosts []string
+	for _, value := range values {
+		if host := github.NormalizeHost(value); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
 func expandPath(path string) (string, error) {
 	if path == "" {
 		return path, nil
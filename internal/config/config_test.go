@@ -13,6 +13,7 @@ func TestLoadConfigFile(t *testing.T) {
 	data := []byte("projects_dir: ~/Work\n" +
 		"temp_dir: /tmp/custom\n" +
 		"temp_ttl: 12h\n" +
+		"persistent_ttl: 720h\n" +
 		"terminal: iterm2\n")
 	if err := os.WriteFile(configPath, data, 0o644); err != nil {
 		t.Fatalf("write config: %v", err)
@@ -31,6 +32,9 @@ func TestLoadConfigFile(t *testing.T) {
 	if cfg.TempTTL != 12*time.Hour {
 		t.Fatalf("expected temp ttl 12h, got %s", cfg.TempTTL)
 	}
+	if cfg.PersistentTTL != 720*time.Hour {
+		t.Fatalf("expected persistent ttl 720h, got %s", cfg.PersistentTTL)
+	}
 	if cfg.ProjectsDir == "~/Work" {
 		t.Fatalf("expected projects dir to be expanded, got %s", cfg.ProjectsDir)
 	}
@@ -91,3 +95,402 @@ func TestInvalidEnvTTL(t *testing.T) {
 		t.Fatalf("expected error for invalid PRT_TEMP_TTL")
 	}
 }
+
+func TestInvalidEnvPersistentTTL(t *testing.T) {
+	t.Setenv("PRT_PERSISTENT_TTL", "nope")
+	_, err := Load(Overrides{})
+	if err == nil {
+		t.Fatalf("expected error for invalid PRT_PERSISTENT_TTL")
+	}
+}
+
+func TestGitHubBackendFromEnv(t *testing.T) {
+	t.Setenv("PRT_GITHUB_BACKEND", "api")
+
+	cfg, err := Load(Overrides{ConfigPath: filepath.Join(t.TempDir(), "missing.yaml")})
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.GitHubBackend != "api" {
+		t.Fatalf("expected github backend api, got %s", cfg.GitHubBackend)
+	}
+}
+
+func TestInvalidGitHubBackend(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "config.yaml")
+	if err := os.WriteFile(configPath, []byte("github_backend: rest\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	if _, err := Load(Overrides{ConfigPath: configPath}); err == nil {
+		t.Fatalf("expected error for invalid github_backend")
+	}
+}
+
+func TestGitHubHostsFromEnv(t *testing.T) {
+	t.Setenv("PRT_GITHUB_HOSTS", "GHE.example.com, https://git.corp.internal/ ,")
+
+	cfg, err := Load(Overrides{ConfigPath: filepath.Join(t.TempDir(), "missing.yaml")})
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(cfg.GitHubHosts) != 2 || cfg.GitHubHosts[0] != "ghe.example.com" || cfg.GitHubHosts[1] != "git.corp.internal" {
+		t.Fatalf("unexpected github hosts: %v", cfg.GitHubHosts)
+	}
+}
+
+func TestTrustedReposFromEnv(t *testing.T) {
+	t.Setenv("PRT_TRUSTED_REPOS", "owner/a, owner/b ,")
+
+	cfg, err := Load(Overrides{ConfigPath: filepath.Join(t.TempDir(), "missing.yaml")})
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(cfg.TrustedRepos) != 2 || !IsTrusted(cfg.TrustedRepos, "owner/b") {
+		t.Fatalf("unexpected trusted repos: %q", cfg.TrustedRepos)
+	}
+}
+
+func TestOnForcePush(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "config.yaml")
+	if err := os.WriteFile(configPath, []byte("on_force_push: Reset\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, err := Load(Overrides{ConfigPath: configPath})
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.OnForcePush != "reset" {
+		t.Fatalf("expected on_force_push reset, got %s", cfg.OnForcePush)
+	}
+
+	t.Setenv("PRT_ON_FORCE_PUSH", "discard")
+	if _, err := Load(Overrides{ConfigPath: configPath}); err == nil {
+		t.Fatalf("expected error for invalid PRT_ON_FORCE_PUSH")
+	}
+}
+
+func TestUpdateOnReuse(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "config.yaml")
+	if err := os.WriteFile(configPath, []byte("update_on_reuse: true\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, err := Load(Overrides{ConfigPath: configPath})
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if !cfg.UpdateOnReuse {
+		t.Fatalf("expected update_on_reuse from config")
+	}
+
+	offPath := filepath.Join(tempDir, "off.yaml")
+	if err := os.WriteFile(offPath, []byte("update_on_reuse: false\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cfg.UpdateOnReuse = true
+	if err := applyFileConfig(&cfg, offPath); err != nil {
+		t.Fatalf("apply config: %v", err)
+	}
+	if cfg.UpdateOnReuse {
+		t.Fatalf("expected an explicit update_on_reuse: false to override an earlier true")
+	}
+
+	t.Setenv("PRT_UPDATE_ON_REUSE", "0")
+	cfg, err = Load(Overrides{ConfigPath: configPath})
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.UpdateOnReuse {
+		t.Fatalf("expected PRT_UPDATE_ON_REUSE=0 to disable updates")
+	}
+}
+
+func TestOpenWith(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "config.yaml")
+	if err := os.WriteFile(configPath, []byte("open_with: Both\neditor: zed\neditor_first_changed: true\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, err := Load(Overrides{ConfigPath: configPath})
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.OpenWith != "both" || cfg.Editor != "zed" || !cfg.EditorFirstChanged {
+		t.Fatalf("unexpected editor config: %+v", cfg)
+	}
+
+	cfg, err = Load(Overrides{ConfigPath: configPath, Editor: "cursor"})
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.Editor != "cursor" {
+		t.Fatalf("expected editor override, got %s", cfg.Editor)
+	}
+
+	t.Setenv("PRT_OPEN_WITH", "window")
+	if _, err := Load(Overrides{ConfigPath: configPath}); err == nil {
+		t.Fatalf("expected error for invalid open_with")
+	}
+}
+
+func TestTerminalCommand(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "config.yaml")
+	if err := os.WriteFile(configPath, []byte("terminal_command: [wezterm, cli, spawn, --cwd, \"{path}\"]\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, err := Load(Overrides{ConfigPath: configPath})
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(cfg.TerminalCommand) != 5 || cfg.TerminalCommand[4] != "{path}" || cfg.TerminalShell {
+		t.Fatalf("unexpected terminal_command: %q (shell %v)", cfg.TerminalCommand, cfg.TerminalShell)
+	}
+
+	// A one-element list is still a program to run directly.
+	if err := os.WriteFile(configPath, []byte("terminal_command: [\"/opt/My Term/bin/term\"]\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cfg, err = Load(Overrides{ConfigPath: configPath})
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(cfg.TerminalCommand) != 1 || cfg.TerminalShell {
+		t.Fatalf("expected a single argument without a shell, got %q (shell %v)", cfg.TerminalCommand, cfg.TerminalShell)
+	}
+
+	if err := os.WriteFile(configPath, []byte("terminal_command: myterm --cd {path}\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cfg, err = Load(Overrides{ConfigPath: configPath})
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(cfg.TerminalCommand) != 1 || cfg.TerminalCommand[0] != "myterm --cd {path}" || !cfg.TerminalShell {
+		t.Fatalf("expected a shell command line, got %q (shell %v)", cfg.TerminalCommand, cfg.TerminalShell)
+	}
+
+	t.Setenv("PRT_TERMINAL_COMMAND", "otherterm {path}")
+	if err := os.WriteFile(configPath, []byte("terminal_command: [myterm]\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cfg, err = Load(Overrides{ConfigPath: configPath})
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(cfg.TerminalCommand) != 1 || cfg.TerminalCommand[0] != "otherterm {path}" || !cfg.TerminalShell {
+		t.Fatalf("expected PRT_TERMINAL_COMMAND as a shell command line, got %q (shell %v)", cfg.TerminalCommand, cfg.TerminalShell)
+	}
+}
+
+func TestHooks(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "config.yaml")
+	data := "" +
+		"hooks:\n" +
+		"  timeout: 90s\n" +
+		"  post_create:\n" +
+		"    \"octo/*\": [uv sync, direnv allow]\n" +
+		"    \"*\": cp ../.env .\n" +
+		"  post_reuse:\n" +
+		"    octo/repo: uv sync\n"
+	if err := os.WriteFile(configPath, []byte(data), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, err := Load(Overrides{ConfigPath: configPath})
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.Hooks.Timeout != 90*time.Second {
+		t.Fatalf("expected hooks timeout 90s, got %s", cfg.Hooks.Timeout)
+	}
+	if got := cfg.Hooks.PostCreate["octo/*"]; len(got) != 2 || got[1] != "direnv allow" {
+		t.Fatalf("unexpected post_create hooks: %v", cfg.Hooks.PostCreate)
+	}
+	if got := cfg.Hooks.PostCreate["*"]; len(got) != 1 || got[0] != "cp ../.env ." {
+		t.Fatalf("expected a single command string, got %v", got)
+	}
+	if got := cfg.Hooks.PostReuse["octo/repo"]; len(got) != 1 {
+		t.Fatalf("unexpected post_reuse hooks: %v", cfg.Hooks.PostReuse)
+	}
+}
+
+func TestParseProject(t *testing.T) {
+	project, err := ParseProject([]byte("setup: npm ci\ncopy: [.env, ./config/local.yml]\nsparse: [src]\n"))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(project.Setup) != 1 || project.Setup[0] != "npm ci" {
+		t.Fatalf("unexpected setup: %v", project.Setup)
+	}
+	if len(project.Copy) != 2 || project.Copy[1] != "config/local.yml" {
+		t.Fatalf("unexpected copy: %v", project.Copy)
+	}
+	if project.Submodules.Policy != "" {
+		t.Fatalf("expected no submodules policy, got %s", project.Submodules.Policy)
+	}
+
+	for _, data := range []string{"copy: [../secrets]\n", "copy: [/etc/passwd]\n", "submodules: sometimes\n"} {
+		if _, err := ParseProject([]byte(data)); err == nil {
+			t.Fatalf("expected error for %q", data)
+		}
+	}
+}
+
+func TestIsTrusted(t *testing.T) {
+	cases := []struct {
+		patterns []string
+		repo     string
+		trusted  bool
+	}{
+		{[]string{"octo/*"}, "Octo/Repo", true},
+		{[]string{"octo/repo"}, "octo/other", false},
+		{[]string{"*"}, "anyone/anything", true},
+		{nil, "octo/repo", false},
+	}
+	for _, tc := range cases {
+		if got := IsTrusted(tc.patterns, tc.repo); got != tc.trusted {
+			t.Fatalf("IsTrusted(%v, %s) = %v, want %v", tc.patterns, tc.repo, got, tc.trusted)
+		}
+	}
+}
+
+func TestCopyFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "config.yaml")
+	data := "" +
+		"copy_files:\n" +
+		"  \"octo/*\": [.env.local, .vscode/settings.json]\n" +
+		"  octo/repo:\n" +
+		"    copy: .env\n" +
+		"    symlink: [.idea/]\n"
+	if err := os.WriteFile(configPath, []byte(data), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, err := Load(Overrides{ConfigPath: configPath})
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if rule := cfg.CopyFiles["octo/*"]; len(rule.Copy) != 2 || len(rule.Symlink) != 0 {
+		t.Fatalf("unexpected list rule: %+v", rule)
+	}
+	if rule := cfg.CopyFiles["octo/repo"]; len(rule.Copy) != 1 || len(rule.Symlink) != 1 || rule.Symlink[0] != ".idea" {
+		t.Fatalf("unexpected mapping rule: %+v", rule)
+	}
+
+	if err := os.WriteFile(configPath, []byte("copy_files:\n  \"*\": [../outside]\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, err := Load(Overrides{ConfigPath: configPath}); err == nil {
+		t.Fatalf("expected error for a path outside the repository")
+	}
+}
+
+func TestSubmodules(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "config.yaml")
+	data := "" +
+		"submodules:\n" +
+		"  policy: shallow\n" +
+		"  jobs: 4\n" +
+		"  repos:\n" +
+		"    \"octo/*\": none\n" +
+		"    octo/mono:\n" +
+		"      policy: list\n" +
+		"      paths: [vendor/lib]\n"
+	if err := os.WriteFile(configPath, []byte(data), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, err := Load(Overrides{ConfigPath: configPath})
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if got := cfg.SubmodulesFor("other/repo", nil); got.Policy != SubmodulesShallow || got.Jobs != 4 {
+		t.Fatalf("unexpected global submodules: %+v", got)
+	}
+	project := &Project{Submodules: Submodules{Policy: SubmodulesRecursive, Depth: 2}}
+	if got := cfg.SubmodulesFor("other/repo", project); got.Policy != SubmodulesRecursive || got.Depth != 2 || got.Jobs != 4 {
+		t.Fatalf("expected .prt.yaml to override global submodules, got %+v", got)
+	}
+	if got := cfg.SubmodulesFor("octo/repo", project); got.Policy != SubmodulesNone {
+		t.Fatalf("expected per-repository setting to win, got %+v", got)
+	}
+	if got := cfg.SubmodulesFor("octo/mono", nil); got.Policy != SubmodulesList || len(got.Paths) != 1 || got.Paths[0] != "vendor/lib" {
+		t.Fatalf("expected later patterns to override earlier ones, got %+v", got)
+	}
+	if got := (Config{}).SubmodulesFor("octo/repo", nil); got.Policy != SubmodulesRecursive {
+		t.Fatalf("expected recursive by default, got %+v", got)
+	}
+
+	t.Setenv("PRT_SUBMODULES", "sometimes")
+	if _, err := Load(Overrides{ConfigPath: configPath}); err == nil {
+		t.Fatalf("expected error for an invalid submodules policy")
+	}
+}
+
+func TestClone(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "config.yaml")
+	data := "clone:\n  depth: 50\n  filter: blob:none\n"
+	if err := os.WriteFile(configPath, []byte(data), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, err := Load(Overrides{ConfigPath: configPath})
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.Clone != (Clone{Depth: 50, Filter: "blob:none"}) {
+		t.Fatalf("unexpected clone config: %+v", cfg.Clone)
+	}
+
+	t.Setenv("PRT_CLONE_SINGLE_BRANCH", "1")
+	cfg, err = Load(Overrides{ConfigPath: configPath, CloneDepth: 1, CloneFilter: "tree:0"})
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.Clone != (Clone{Depth: 1, Filter: "tree:0", SingleBranch: true}) {
+		t.Fatalf("expected overrides to take precedence, got %+v", cfg.Clone)
+	}
+
+	if _, err := Load(Overrides{ConfigPath: configPath, CloneFilter: "sparse:oid=abc"}); err == nil {
+		t.Fatalf("expected error for an unsupported filter")
+	}
+	t.Setenv("PRT_CLONE_DEPTH", "shallow")
+	if _, err := Load(Overrides{ConfigPath: configPath}); err == nil {
+		t.Fatalf("expected error for an invalid PRT_CLONE_DEPTH")
+	}
+}
+
+func TestCacheDir(t *testing.T) {
+	cfg, err := Load(Overrides{ConfigPath: filepath.Join(t.TempDir(), "missing.yaml")})
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.CacheDir != "" {
+		t.Fatalf("expected the cache to be off by default, got %s", cfg.CacheDir)
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skipf("no home dir: %v", err)
+	}
+	t.Setenv("PRT_CACHE_DIR", "~/.cache/prt/mirrors")
+	cfg, err = Load(Overrides{ConfigPath: filepath.Join(t.TempDir(), "missing.yaml")})
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if expected := filepath.Join(home, ".cache", "prt", "mirrors"); cfg.CacheDir != expected {
+		t.Fatalf("expected %s, got %s", expected, cfg.CacheDir)
+	}
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectFile is the name of the repo-local config file.
+const ProjectFile = ".prt.yaml"
+
+// Project is a repository's own .prt.yaml. It is read from the PR's base
+// branch, never from the PR head.
+type Project struct {
+	// Setup commands run in new worktrees, only for trusted repositories.
+	Setup []string
+	// Copy lists paths, relative to the repository root, copied from the
+	// main checkout into new worktrees.
+	Copy []string
+	// Sparse lists directories for a cone-mode sparse checkout.
+	Sparse []string
+	// Submodules overrides the global submodules setting; a user's
+	// per-repository setting still takes precedence.
+	Submodules Submodules
+}
+
+type fileProject struct {
+	Setup      stringList     `yaml:"setup"`
+	Copy       stringList     `yaml:"copy"`
+	Sparse     stringList     `yaml:"sparse"`
+	Submodules fileSubmodules `yaml:"submodules"`
+}
+
+// ParseProject parses the contents of a .prt.yaml file.
+func ParseProject(data []byte) (Project, error) {
+	var file fileProject
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return Project{}, fmt.Errorf("parse %s: %w", ProjectFile, err)
+	}
+
+	project := Project{
+		Setup:  file.Setup,
+		Sparse: file.Sparse,
+	}
+	for _, entry := range file.Copy {
+		cleaned, err := cleanRelativePath(entry)
+		if err != nil {
+			return Project{}, fmt.Errorf("invalid copy entry in %s: %w", ProjectFile, err)
+		}
+		project.Copy = append(project.Copy, cleaned)
+	}
+	submodules, err := file.Submodules.parse()
+	if err != nil {
+		return Project{}, fmt.Errorf("invalid submodules in %s: %w", ProjectFile, err)
+	}
+	project.Submodules = submodules
+	return project, nil
+}
+
+// IsTrusted reports whether repo ("owner/repo") matches one of the
+// trusted_repos patterns.
+func IsTrusted(patterns []string, repo string) bool {
+	for _, pattern := range patterns {
+		if MatchesRepo(pattern, repo) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchesRepo reports whether repo ("owner/repo") matches pattern. Patterns
+// use path.Match syntax, compared case-insensitively, so "*" only spans one
+// segment; a bare "*" matches every repository.
+func MatchesRepo(pattern string, repo string) bool {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	if pattern == "*" {
+		return true
+	}
+	ok, err := path.Match(pattern, strings.ToLower(repo))
+	return err == nil && ok
+}
+
+// cleanRelativePath rejects absolute paths and paths that leave the
+// repository.
+func cleanRelativePath(value string) (string, error) {
+	cleaned := path.Clean(strings.ReplaceAll(strings.TrimSpace(value), "\\", "/"))
+	if cleaned == "." || path.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("%q must be a path inside the repository", value)
+	}
+	return cleaned, nil
+}
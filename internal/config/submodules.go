@@ -0,0 +1,142 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Submodule policies for Submodules.Policy.
+const (
+	// SubmodulesRecursive initializes every submodule recursively.
+	SubmodulesRecursive = "recursive"
+	// SubmodulesShallow is SubmodulesRecursive with a depth of 1.
+	SubmodulesShallow = "shallow"
+	// SubmodulesNone leaves submodules uninitialized.
+	SubmodulesNone = "none"
+	// SubmodulesList initializes only Submodules.Paths.
+	SubmodulesList = "list"
+)
+
+// Submodules controls how submodules are initialized in worktrees. Zero
+// fields are unset, so settings can be layered with Merge.
+type Submodules struct {
+	Policy string
+	// Paths selects submodules for the list policy.
+	Paths []string
+	Depth int
+	Jobs  int
+}
+
+// Merge returns s with the fields set in other replacing its own.
+func (s Submodules) Merge(other Submodules) Submodules {
+	if other.Policy != "" {
+		s.Policy = other.Policy
+	}
+	if len(other.Paths) > 0 {
+		s.Paths = other.Paths
+	}
+	if other.Depth > 0 {
+		s.Depth = other.Depth
+	}
+	if other.Jobs > 0 {
+		s.Jobs = other.Jobs
+	}
+	return s
+}
+
+// fileSubmodules accepts a policy string or a mapping with policy, paths,
+// depth, and jobs.
+type fileSubmodules struct {
+	Policy string     `yaml:"policy"`
+	Paths  stringList `yaml:"paths"`
+	Depth  int        `yaml:"depth"`
+	Jobs   int        `yaml:"jobs"`
+}
+
+func (f *fileSubmodules) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		f.Policy = value.Value
+		return nil
+	}
+	type plain fileSubmodules
+	return value.Decode((*plain)(f))
+}
+
+// fileGlobalSubmodules is the top-level submodules setting, which may also
+// carry per-repository overrides keyed by owner/repo glob.
+type fileGlobalSubmodules struct {
+	Policy string                    `yaml:"policy"`
+	Paths  stringList                `yaml:"paths"`
+	Depth  int                       `yaml:"depth"`
+	Jobs   int                       `yaml:"jobs"`
+	Repos  map[string]fileSubmodules `yaml:"repos"`
+}
+
+func (f *fileGlobalSubmodules) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		f.Policy = value.Value
+		return nil
+	}
+	type plain fileGlobalSubmodules
+	return value.Decode((*plain)(f))
+}
+
+func (f fileGlobalSubmodules) parse() (Submodules, error) {
+	return fileSubmodules{Policy: f.Policy, Paths: f.Paths, Depth: f.Depth, Jobs: f.Jobs}.parse()
+}
+
+func (f fileSubmodules) parse() (Submodules, error) {
+	submodules := Submodules{
+		Policy: strings.ToLower(strings.TrimSpace(f.Policy)),
+		Depth:  f.Depth,
+		Jobs:   f.Jobs,
+	}
+	if err := validateSubmodulePolicy(submodules.Policy); err != nil {
+		return Submodules{}, err
+	}
+	if f.Depth < 0 || f.Jobs < 0 {
+		return Submodules{}, fmt.Errorf("submodule depth and jobs must not be negative")
+	}
+	for _, entry := range f.Paths {
+		cleaned, err := cleanRelativePath(entry)
+		if err != nil {
+			return Submodules{}, err
+		}
+		submodules.Paths = append(submodules.Paths, cleaned)
+	}
+	return submodules, nil
+}
+
+func validateSubmodulePolicy(value string) error {
+	switch value {
+	case "", SubmodulesRecursive, SubmodulesShallow, SubmodulesNone, SubmodulesList:
+		return nil
+	default:
+		return fmt.Errorf("invalid submodules policy %q (expected recursive, shallow, none, or list)", value)
+	}
+}
+
+// SubmodulesFor returns the effective submodule settings for repo
+// ("owner/repo"): the global setting, then project (the repository's
+// .prt.yaml, if any), then matching per-repository overrides in sorted
+// pattern order.
+func (c Config) SubmodulesFor(repo string, project *Project) Submodules {
+	effective := Submodules{Policy: SubmodulesRecursive}.Merge(c.Submodules)
+	if project != nil {
+		effective = effective.Merge(project.Submodules)
+	}
+	patterns := make([]string, 0, len(c.RepoSubmodules))
+	for pattern := range c.RepoSubmodules {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+	for _, pattern := range patterns {
+		if MatchesRepo(pattern, repo) {
+			effective = effective.Merge(c.RepoSubmodules[pattern])
+		}
+	}
+	return effective
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os/exec"
+	"strconv"
 	"strings"
 )
 
@@ -82,9 +83,46 @@ func (c *Client) IsGitRepo(ctx context.Context, repoDir string) (bool, error) {
 	return output != "", nil
 }
 
+// CloneOptions configures Clone and CloneBare.
+type CloneOptions struct {
+	// Depth creates a shallow clone with that many commits; 0 clones all.
+	Depth int
+	// Filter requests a partial clone, e.g. "blob:none" or "tree:0".
+	Filter       string
+	SingleBranch bool
+	// Reference is a local repository to borrow objects from through
+	// objects/info/alternates.
+	Reference string
+	// Dissociate copies the borrowed objects once the clone is done, so it
+	// no longer depends on Reference.
+	Dissociate bool
+}
+
+func (o CloneOptions) args() []string {
+	var args []string
+	if o.Depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(o.Depth))
+	}
+	if o.Filter != "" {
+		args = append(args, "--filter="+o.Filter)
+	}
+	if o.SingleBranch {
+		args = append(args, "--single-branch")
+	}
+	if o.Reference != "" {
+		args = append(args, "--reference", o.Reference)
+		if o.Dissociate {
+			args = append(args, "--dissociate")
+		}
+	}
+	return args
+}
+
 // Clone clones a repository into dest.
-func (c *Client) Clone(ctx context.Context, url string, dest string) error {
-	_, err := c.runner.Run(ctx, "", "git", "clone", url, dest)
+func (c *Client) Clone(ctx context.Context, url string, dest string, opts CloneOptions) error {
+	args := append([]string{"clone"}, opts.args()...)
+	args = append(args, url, dest)
+	_, err := c.runner.Run(ctx, "", "git", args...)
 	if err != nil {
 		return fmt.Errorf("git clone failed: %w", err)
 	}
@@ -92,11 +130,8 @@ func (c *Client) Clone(ctx context.Context, url string, dest string) error {
 }
 
 // CloneBare clones a repository as bare into dest.
-func (c *Client) CloneBare(ctx context.Context, url string, dest string, depth int) error {
-	args := []string{"clone", "--bare"}
-	if depth > 0 {
-		args = append(args, "--depth", fmt.Sprintf("%d", depth))
-	}
+func (c *Client) CloneBare(ctx context.Context, url string, dest string, opts CloneOptions) error {
+	args := append([]string{"clone", "--bare"}, opts.args()...)
 	args = append(args, url, dest)
 	_, err := c.runner.Run(ctx, "", "git", args...)
 	if err != nil {
@@ -105,6 +140,53 @@ func (c *Client) CloneBare(ctx context.Context, url string, dest string, depth i
 	return nil
 }
 
+// CloneMirror clones a mirror of the repository at url into dest.
+func (c *Client) CloneMirror(ctx context.Context, url string, dest string) error {
+	if _, err := c.runner.Run(ctx, "", "git", "clone", "--mirror", url, dest); err != nil {
+		return fmt.Errorf("git clone --mirror failed: %w", err)
+	}
+	return nil
+}
+
+// FetchPrune fetches remote into repoDir using its configured refspecs and
+// deletes refs the remote no longer has.
+func (c *Client) FetchPrune(ctx context.Context, repoDir string, remote string) error {
+	if _, err := c.runner.Run(ctx, repoDir, "git", "fetch", "--prune", remote); err != nil {
+		return fmt.Errorf("git fetch --prune failed: %w", err)
+	}
+	return nil
+}
+
+// GC runs git gc in repoDir.
+func (c *Client) GC(ctx context.Context, repoDir string) error {
+	if _, err := c.runner.Run(ctx, repoDir, "git", "gc", "--quiet"); err != nil {
+		return fmt.Errorf("git gc failed: %w", err)
+	}
+	return nil
+}
+
+// IsShallow reports whether repoDir is a shallow clone.
+func (c *Client) IsShallow(ctx context.Context, repoDir string) (bool, error) {
+	output, err := c.runner.Run(ctx, repoDir, "git", "rev-parse", "--is-shallow-repository")
+	if err != nil {
+		return false, fmt.Errorf("git rev-parse --is-shallow-repository failed: %w", err)
+	}
+	return strings.TrimSpace(output) == "true", nil
+}
+
+// IsPartialClone reports whether repoDir was cloned with a filter, so some
+// objects are only available from its promisor remote.
+func (c *Client) IsPartialClone(ctx context.Context, repoDir string) (bool, error) {
+	output, err := c.runner.Run(ctx, repoDir, "git", "config", "--get", "extensions.partialClone")
+	if err != nil {
+		if isExitCode(err, 1) {
+			return false, nil
+		}
+		return false, fmt.Errorf("git config --get failed: %w", err)
+	}
+	return strings.TrimSpace(output) != "", nil
+}
+
 // Fetch fetches refspec from remote into repoDir.
 func (c *Client) Fetch(ctx context.Context, repoDir string, remote string, refspec string) error {
 	_, err := c.runner.Run(ctx, repoDir, "git", "fetch", remote, refspec)
@@ -114,20 +196,116 @@ func (c *Client) Fetch(ctx context.Context, repoDir string, remote string, refsp
 	return nil
 }
 
-// FetchBranch fetches a single branch from remote into repoDir.
+// RefetchAll fetches every remote into repoDir without negotiating, so
+// objects are downloaded again even when refs say they are present. It
+// repairs a repository that lost its alternate object store.
+func (c *Client) RefetchAll(ctx context.Context, repoDir string) error {
+	if _, err := c.runner.Run(ctx, repoDir, "git", "fetch", "--refetch", "--all"); err != nil {
+		return fmt.Errorf("git fetch --refetch failed: %w", err)
+	}
+	return nil
+}
+
+// ShallowFetch sets how much history a fetch into a shallow repository
+// brings in. At most one field should be set.
+type ShallowFetch struct {
+	// Depth fetches that many commits from the tip of each fetched ref.
+	Depth int
+	// Deepen extends the current shallow boundary by that many commits.
+	Deepen    int
+	Unshallow bool
+}
+
+// FetchShallow fetches refspec from remote into the shallow repository at
+// repoDir, limiting or extending history as shallow asks.
+func (c *Client) FetchShallow(ctx context.Context, repoDir string, remote string, refspec string, shallow ShallowFetch) error {
+	args := []string{"fetch"}
+	switch {
+	case shallow.Unshallow:
+		args = append(args, "--unshallow")
+	case shallow.Deepen > 0:
+		args = append(args, "--deepen="+strconv.Itoa(shallow.Deepen))
+	case shallow.Depth > 0:
+		args = append(args, "--depth="+strconv.Itoa(shallow.Depth))
+	}
+	args = append(args, remote, refspec)
+	if _, err := c.runner.Run(ctx, repoDir, "git", args...); err != nil {
+		return fmt.Errorf("git fetch failed: %w", err)
+	}
+	return nil
+}
+
+// FetchBranch fetches a single branch from remote into repoDir, updating
+// its remote-tracking ref even when the remote's configured refspec does
+// not cover it (as in single-branch clones).
 func (c *Client) FetchBranch(ctx context.Context, repoDir string, remote string, branch string) error {
-	return c.Fetch(ctx, repoDir, remote, branch)
+	return c.Fetch(ctx, repoDir, remote, BranchRefspec(remote, branch))
 }
 
-// SubmoduleUpdate initializes and updates submodules recursively in repoDir.
-func (c *Client) SubmoduleUpdate(ctx context.Context, repoDir string) error {
-	_, err := c.runner.Run(ctx, repoDir, "git", "submodule", "update", "--init", "--recursive")
-	if err != nil {
+// BranchRefspec maps branch on remote to its remote-tracking ref.
+func BranchRefspec(remote string, branch string) string {
+	return fmt.Sprintf("+refs/heads/%s:refs/remotes/%s/%s", branch, remote, branch)
+}
+
+// SubmoduleOptions configures SubmoduleUpdate.
+type SubmoduleOptions struct {
+	Recursive bool
+	// Depth limits the history fetched for each submodule; 0 fetches all.
+	Depth int
+	// Jobs is the number of submodules fetched in parallel; 0 uses git's
+	// default.
+	Jobs int
+	// Reference is a repository to borrow objects from, usually the main
+	// checkout's copy of the submodule.
+	Reference string
+	// Paths limits the update to these submodule paths; empty updates all.
+	Paths []string
+}
+
+// SubmoduleUpdate initializes and updates submodules in repoDir.
+func (c *Client) SubmoduleUpdate(ctx context.Context, repoDir string, opts SubmoduleOptions) error {
+	args := []string{"submodule", "update", "--init"}
+	if opts.Recursive {
+		args = append(args, "--recursive")
+	}
+	if opts.Depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(opts.Depth))
+	}
+	if opts.Jobs > 0 {
+		args = append(args, "--jobs", strconv.Itoa(opts.Jobs))
+	}
+	if opts.Reference != "" {
+		args = append(args, "--reference", opts.Reference)
+	}
+	if len(opts.Paths) > 0 {
+		args = append(args, "--")
+		args = append(args, opts.Paths...)
+	}
+	if _, err := c.runner.Run(ctx, repoDir, "git", args...); err != nil {
 		return fmt.Errorf("git submodule update failed: %w", err)
 	}
 	return nil
 }
 
+// Submodule is a submodule declared in .gitmodules.
+type Submodule struct {
+	Name string
+	Path string
+}
+
+// Submodules returns the submodules declared in repoDir's .gitmodules.
+func (c *Client) Submodules(ctx context.Context, repoDir string) ([]Submodule, error) {
+	output, err := c.runner.Run(ctx, repoDir, "git", "config", "--file", ".gitmodules", "--get-regexp", `^submodule\..*\.path$`)
+	if err != nil {
+		// Exit code 1: no .gitmodules, or no submodules in it.
+		if isExitCode(err, 1) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("git config --get-regexp failed: %w", err)
+	}
+	return parseSubmodulePaths(output), nil
+}
+
 // WorktreeAdd adds a worktree for branch at worktreePath.
 func (c *Client) WorktreeAdd(ctx context.Context, repoDir string, worktreePath string, branch string) error {
 	_, err := c.runner.Run(ctx, repoDir, "git", "worktree", "add", worktreePath, branch)
@@ -207,6 +385,30 @@ func (c *Client) RemoteURL(ctx context.Context, repoDir string, name string) (st
 	return strings.TrimSpace(output), nil
 }
 
+// RemoveRemote deletes remote name from repoDir.
+func (c *Client) RemoveRemote(ctx context.Context, repoDir string, name string) error {
+	_, err := c.runner.Run(ctx, repoDir, "git", "remote", "remove", name)
+	if err != nil {
+		return fmt.Errorf("git remote remove failed: %w", err)
+	}
+	return nil
+}
+
+// Remotes lists the remote names defined in repoDir.
+func (c *Client) Remotes(ctx context.Context, repoDir string) ([]string, error) {
+	output, err := c.runner.Run(ctx, repoDir, "git", "remote")
+	if err != nil {
+		return nil, fmt.Errorf("git remote failed: %w", err)
+	}
+	var remotes []string
+	for remote := range strings.SplitSeq(output, "\n") {
+		if remote = strings.TrimSpace(remote); remote != "" {
+			remotes = append(remotes, remote)
+		}
+	}
+	return remotes, nil
+}
+
 // SetRemoteURL updates the configured URL for remote name.
 func (c *Client) SetRemoteURL(ctx context.Context, repoDir string, name string, url string) error {
 	_, err := c.runner.Run(ctx, repoDir, "git", "remote", "set-url", name, url)
@@ -225,6 +427,30 @@ func (c *Client) SetUpstream(ctx context.Context, repoDir string, branch string,
 	return nil
 }
 
+// DeleteBranch deletes local branch from repoDir. Without force, git refuses
+// to delete a branch that is not merged into its upstream (or HEAD).
+func (c *Client) DeleteBranch(ctx context.Context, repoDir string, branch string, force bool) error {
+	flag := "-d"
+	if force {
+		flag = "-D"
+	}
+	_, err := c.runner.Run(ctx, repoDir, "git", "branch", flag, branch)
+	if err != nil {
+		return fmt.Errorf("git branch %s failed: %w", flag, err)
+	}
+	return nil
+}
+
+// BranchUpstreamRemotes maps each local branch with an upstream to the
+// upstream's remote name.
+func (c *Client) BranchUpstreamRemotes(ctx context.Context, repoDir string) (map[string]string, error) {
+	output, err := c.runner.Run(ctx, repoDir, "git", "for-each-ref", "--format=%(refname:short) %(upstream:remotename)", "refs/heads")
+	if err != nil {
+		return nil, fmt.Errorf("git for-each-ref failed: %w", err)
+	}
+	return parseBranchUpstreams(output), nil
+}
+
 // ConfigSet writes a git config key in repoDir.
 func (c *Client) ConfigSet(ctx context.Context, repoDir string, key string, value string) error {
 	_, err := c.runner.Run(ctx, repoDir, "git", "config", key, value)
@@ -277,6 +503,179 @@ func (c *Client) WorktreeAddBranch(ctx context.Context, repoDir string, worktree
 	return nil
 }
 
+// WorktreeAddDetached adds a worktree at worktreePath with a detached HEAD at commit.
+func (c *Client) WorktreeAddDetached(ctx context.Context, repoDir string, worktreePath string, commit string) error {
+	_, err := c.runner.Run(ctx, repoDir, "git", "worktree", "add", "--detach", worktreePath, commit)
+	if err != nil {
+		return fmt.Errorf("git worktree add --detach failed: %w", err)
+	}
+	return nil
+}
+
+// RevParse resolves rev to a full object name in repoDir.
+func (c *Client) RevParse(ctx context.Context, repoDir string, rev string) (string, error) {
+	output, err := c.runner.Run(ctx, repoDir, "git", "rev-parse", "--verify", "--quiet", rev)
+	if err != nil {
+		return "", fmt.Errorf("git rev-parse %s failed: %w", rev, err)
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// ResetHard resets the current branch, index, and working tree in repoDir to rev.
+func (c *Client) ResetHard(ctx context.Context, repoDir string, rev string) error {
+	_, err := c.runner.Run(ctx, repoDir, "git", "reset", "--hard", rev)
+	if err != nil {
+		return fmt.Errorf("git reset --hard failed: %w", err)
+	}
+	return nil
+}
+
+// MergeFastForward fast-forwards the current branch in repoDir to rev and
+// fails if that is not possible.
+func (c *Client) MergeFastForward(ctx context.Context, repoDir string, rev string) error {
+	_, err := c.runner.Run(ctx, repoDir, "git", "merge", "--ff-only", rev)
+	if err != nil {
+		return fmt.Errorf("git merge --ff-only failed: %w", err)
+	}
+	return nil
+}
+
+// TopLevel returns the root directory of the worktree containing dir.
+func (c *Client) TopLevel(ctx context.Context, dir string) (string, error) {
+	output, err := c.runner.Run(ctx, dir, "git", "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", fmt.Errorf("git rev-parse --show-toplevel failed: %w", err)
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// CurrentBranch returns the branch checked out in repoDir, or "" when HEAD
+// is detached.
+func (c *Client) CurrentBranch(ctx context.Context, repoDir string) (string, error) {
+	output, err := c.runner.Run(ctx, repoDir, "git", "symbolic-ref", "--quiet", "--short", "HEAD")
+	if err != nil {
+		if isExitCode(err, 1) {
+			return "", nil
+		}
+		return "", fmt.Errorf("git symbolic-ref failed: %w", err)
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// Upstream returns the upstream of the current branch in repoDir (for
+// example origin/feature), or "" when none is configured.
+func (c *Client) Upstream(ctx context.Context, repoDir string) (string, error) {
+	output, err := c.runner.Run(ctx, repoDir, "git", "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}")
+	if err != nil {
+		if strings.Contains(output, "no upstream") || strings.Contains(output, "HEAD does not point to a branch") {
+			return "", nil
+		}
+		return "", fmt.Errorf("git rev-parse @{upstream} failed: %w", err)
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// AheadBehind returns how many commits local has that upstream lacks
+// (ahead) and how many upstream has that local lacks (behind).
+func (c *Client) AheadBehind(ctx context.Context, repoDir string, local string, upstream string) (int, int, error) {
+	output, err := c.runner.Run(ctx, repoDir, "git", "rev-list", "--left-right", "--count", local+"..."+upstream)
+	if err != nil {
+		return 0, 0, fmt.Errorf("git rev-list --left-right failed: %w", err)
+	}
+	return parseLeftRightCounts(output)
+}
+
+// CountCommits counts commits reachable from rev but not from any of exclude.
+func (c *Client) CountCommits(ctx context.Context, repoDir string, rev string, exclude ...string) (int, error) {
+	args := []string{"rev-list", "--count", rev}
+	for _, excluded := range exclude {
+		args = append(args, "^"+excluded)
+	}
+	output, err := c.runner.Run(ctx, repoDir, "git", args...)
+	if err != nil {
+		return 0, fmt.Errorf("git rev-list --count failed: %w", err)
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(output))
+	if err != nil {
+		return 0, fmt.Errorf("unexpected git rev-list output %q", output)
+	}
+	return count, nil
+}
+
+// MergeBase returns the best common ancestor of a and b.
+func (c *Client) MergeBase(ctx context.Context, repoDir string, a string, b string) (string, error) {
+	output, err := c.runner.Run(ctx, repoDir, "git", "merge-base", a, b)
+	if err != nil {
+		return "", fmt.Errorf("git merge-base failed: %w", err)
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// IsAncestor reports whether ancestor is reachable from descendant.
+func (c *Client) IsAncestor(ctx context.Context, repoDir string, ancestor string, descendant string) (bool, error) {
+	_, err := c.runner.Run(ctx, repoDir, "git", "merge-base", "--is-ancestor", ancestor, descendant)
+	if err != nil {
+		if isExitCode(err, 1) {
+			return false, nil
+		}
+		return false, fmt.Errorf("git merge-base --is-ancestor failed: %w", err)
+	}
+	return true, nil
+}
+
+// ChangedFiles returns the paths with uncommitted changes in repoDir,
+// including untracked files.
+func (c *Client) ChangedFiles(ctx context.Context, repoDir string) ([]string, error) {
+	output, err := c.runner.Run(ctx, repoDir, "git", "status", "--porcelain", "--untracked-files=all")
+	if err != nil {
+		return nil, fmt.Errorf("git status --porcelain failed: %w", err)
+	}
+	return parseStatusPaths(output), nil
+}
+
+// DiffNames returns the paths changed between the merge-base of base and
+// head and head, excluding deleted files.
+func (c *Client) DiffNames(ctx context.Context, repoDir string, base string, head string) ([]string, error) {
+	output, err := c.runner.Run(ctx, repoDir, "git", "diff", "--name-only", "--diff-filter=d", base+"..."+head)
+	if err != nil {
+		return nil, fmt.Errorf("git diff --name-only failed: %w", err)
+	}
+	var paths []string
+	for line := range strings.SplitSeq(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			paths = append(paths, line)
+		}
+	}
+	return paths, nil
+}
+
+// FileAtRev returns the contents of path at rev. ok is false when rev does
+// not contain path.
+func (c *Client) FileAtRev(ctx context.Context, repoDir string, rev string, path string) (string, bool, error) {
+	output, err := c.runner.Run(ctx, repoDir, "git", "ls-tree", "--name-only", rev, "--", path)
+	if err != nil {
+		return "", false, fmt.Errorf("git ls-tree failed: %w", err)
+	}
+	if strings.TrimSpace(output) == "" {
+		return "", false, nil
+	}
+	output, err = c.runner.Run(ctx, repoDir, "git", "show", rev+":"+path)
+	if err != nil {
+		return "", false, fmt.Errorf("git show failed: %w", err)
+	}
+	return output, true, nil
+}
+
+// SparseCheckoutSet limits the worktree at repoDir to dirs using a
+// cone-mode sparse checkout.
+func (c *Client) SparseCheckoutSet(ctx context.Context, repoDir string, dirs []string) error {
+	args := append([]string{"sparse-checkout", "set", "--cone"}, dirs...)
+	if _, err := c.runner.Run(ctx, repoDir, "git", args...); err != nil {
+		return fmt.Errorf("git sparse-checkout set failed: %w", err)
+	}
+	return nil
+}
+
 // OriginURL returns the URL configured for origin.
 func (c *Client) OriginURL(ctx context.Context, repoDir string) (string, error) {
 	return c.RemoteURL(ctx, repoDir, "origin")
@@ -313,6 +712,80 @@ func parseWorktreeList(output string) []Worktree {
 	return worktrees
 }
 
+// parseSubmodulePaths parses "submodule.<name>.path <path>" lines.
+func parseSubmodulePaths(output string) []Submodule {
+	var submodules []Submodule
+	for line := range strings.SplitSeq(output, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok {
+			continue
+		}
+		name, ok := strings.CutPrefix(key, "submodule.")
+		if !ok {
+			continue
+		}
+		name, ok = strings.CutSuffix(name, ".path")
+		if !ok || name == "" {
+			continue
+		}
+		submodules = append(submodules, Submodule{Name: name, Path: strings.TrimSpace(value)})
+	}
+	return submodules
+}
+
+func parseBranchUpstreams(output string) map[string]string {
+	upstreams := make(map[string]string)
+	for line := range strings.SplitSeq(output, "\n") {
+		branch, remote, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok || branch == "" || remote == "" {
+			continue
+		}
+		upstreams[branch] = remote
+	}
+	return upstreams
+}
+
+func parseLeftRightCounts(output string) (int, int, error) {
+	fields := strings.Fields(output)
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("unexpected git rev-list output %q", output)
+	}
+	left, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("unexpected git rev-list output %q", output)
+	}
+	right, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("unexpected git rev-list output %q", output)
+	}
+	return left, right, nil
+}
+
+// parseStatusPaths extracts paths from `git status --porcelain` output. The
+// runner trims the output, which can strip the leading space of the first
+// status code, so the path is taken after the two-character code and trimmed.
+func parseStatusPaths(output string) []string {
+	var paths []string
+	for line := range strings.SplitSeq(output, "\n") {
+		if len(line) < 3 {
+			continue
+		}
+		path := strings.TrimSpace(line[2:])
+		if _, renamed, ok := strings.Cut(path, " -> "); ok {
+			path = renamed
+		}
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+func isExitCode(err error, code int) bool {
+	var exitErr *exec.ExitError
+	return errors.As(err, &exitErr) && exitErr.ExitCode() == code
+}
+
 func branchMatches(ref string, branch string) bool {
 	if ref == branch {
 		return true
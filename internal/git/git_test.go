@@ -127,6 +127,21 @@ func TestWorktreeAddBranchGenericError(t *testing.T) {
 	}
 }
 
+func TestParseBranchUpstreams(t *testing.T) {
+	input := "" +
+		"main origin\n" +
+		"pr/15/feature prt/fork/repo\n" +
+		"local-only \n"
+
+	upstreams := parseBranchUpstreams(input)
+	if len(upstreams) != 2 {
+		t.Fatalf("expected 2 upstreams, got %v", upstreams)
+	}
+	if upstreams["main"] != "origin" || upstreams["pr/15/feature"] != "prt/fork/repo" {
+		t.Fatalf("unexpected upstreams: %v", upstreams)
+	}
+}
+
 type fakeRunner struct {
 	output string
 	err    error
@@ -135,3 +150,64 @@ type fakeRunner struct {
 func (r *fakeRunner) Run(_ context.Context, _ string, _ string, _ ...string) (string, error) {
 	return r.output, r.err
 }
+
+func TestParseLeftRightCounts(t *testing.T) {
+	ahead, behind, err := parseLeftRightCounts("2\t5")
+	if err != nil {
+		t.Fatalf("parseLeftRightCounts: %v", err)
+	}
+	if ahead != 2 || behind != 5 {
+		t.Fatalf("expected 2 ahead and 5 behind, got %d and %d", ahead, behind)
+	}
+	if _, _, err := parseLeftRightCounts("fatal: bad revision"); err == nil {
+		t.Fatalf("expected error for unexpected output")
+	}
+}
+
+func TestParseStatusPaths(t *testing.T) {
+	// The first line lost its leading space to output trimming.
+	input := "M main.go\n" +
+		"MM internal/x.go\n" +
+		"?? notes.txt\n" +
+		"R  old.go -> new.go"
+
+	paths := parseStatusPaths(input)
+	expected := []string{"main.go", "internal/x.go", "notes.txt", "new.go"}
+	if len(paths) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, paths)
+	}
+	for i := range expected {
+		if paths[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, paths)
+		}
+	}
+}
+
+func TestParseSubmodulePaths(t *testing.T) {
+	input := "" +
+		"submodule.vendor/lib.path vendor/lib\n" +
+		"submodule.docs.path third_party/docs\n" +
+		"not a submodule line\n"
+
+	submodules := parseSubmodulePaths(input)
+	expected := []Submodule{{Name: "vendor/lib", Path: "vendor/lib"}, {Name: "docs", Path: "third_party/docs"}}
+	if len(submodules) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, submodules)
+	}
+	for i := range expected {
+		if submodules[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, submodules)
+		}
+	}
+}
+
+func TestCloneOptionsArgs(t *testing.T) {
+	args := CloneOptions{Depth: 1, Filter: "blob:none", SingleBranch: true, Reference: "/src/repo", Dissociate: true}.args()
+	expected := []string{"--depth", "1", "--filter=blob:none", "--single-branch", "--reference", "/src/repo", "--dissociate"}
+	if fmt.Sprint(args) != fmt.Sprint(expected) {
+		t.Fatalf("expected %v, got %v", expected, args)
+	}
+	if args := (CloneOptions{}).args(); len(args) != 0 {
+		t.Fatalf("expected no args for a full clone, got %v", args)
+	}
+}
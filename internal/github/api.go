@@ -0,0 +1,244 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const defaultAPIBaseURL = "https://api.github.com"
+
+// APIClient fetches pull request metadata directly from the GitHub REST API.
+type APIClient struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+	hosts      []string
+	tokenFor   func(host string) (string, error)
+}
+
+// APIClientOptions configures a GitHub API metadata client.
+type APIClientOptions struct {
+	// BaseURL overrides the API endpoint (e.g. an httptest server).
+	BaseURL string
+	// Token overrides token discovery from the environment and gh config.
+	Token      string
+	HTTPClient *http.Client
+	// Hosts lists additional GitHub Enterprise Server hosts to accept.
+	Hosts []string
+}
+
+// NewAPIClient constructs an APIClient using defaults when options are omitted.
+func NewAPIClient(opts APIClientOptions) *APIClient {
+	httpClient := opts.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &APIClient{
+		baseURL:    strings.TrimSuffix(opts.BaseURL, "/"),
+		token:      opts.Token,
+		httpClient: httpClient,
+		hosts:      opts.Hosts,
+		tokenFor:   ResolveToken,
+	}
+}
+
+// FetchPRMetadata loads pull request metadata needed to resolve worktrees.
+func (c *APIClient) FetchPRMetadata(ctx context.Context, prURL string) (PRMetadata, error) {
+	ref, err := ParsePRURLForHosts(prURL, c.hosts)
+	if err != nil {
+		return PRMetadata{}, err
+	}
+
+	var payload restPR
+	path := fmt.Sprintf("/repos/%s/%s/pulls/%d", ref.Owner, ref.Repo, ref.Number)
+	if err := c.getJSON(ctx, ref.hostName(), path, &payload); err != nil {
+		return PRMetadata{}, err
+	}
+
+	baseRepo := baseRepository(ref)
+	headRepo, headRepoMissing, err := repoFromHeadPayload(payload.Head.Repo.toGH(), payload.Head.User.toGHOwner(), ref)
+	if err != nil {
+		return PRMetadata{}, fmt.Errorf("head repository: %w", err)
+	}
+
+	return PRMetadata{
+		Number:          payload.Number,
+		Title:           payload.Title,
+		State:           payload.state(),
+		URL:             payload.HTMLURL,
+		HeadRef:         payload.Head.Ref,
+		BaseRef:         payload.Base.Ref,
+		BaseRepo:        baseRepo,
+		HeadRepo:        headRepo,
+		HeadRepoMissing: headRepoMissing,
+	}, nil
+}
+
+func (c *APIClient) getJSON(ctx context.Context, host string, path string, out any) error {
+	return c.doJSON(ctx, host, http.MethodGet, c.apiBaseURL(host)+path, nil, out)
+}
+
+// doJSON sends a request with an optional JSON body to endpoint and decodes
+// the JSON response into out.
+func (c *APIClient) doJSON(ctx context.Context, host string, method string, endpoint string, body any, out any) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("encode GitHub API request: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reqBody)
+	if err != nil {
+		return fmt.Errorf("build GitHub API request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+
+	token, err := c.resolveToken(host)
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("GitHub API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read GitHub API response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return apiError(resp.StatusCode, respBody, token != "")
+	}
+
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("parse GitHub API response: %w", err)
+	}
+	return nil
+}
+
+// apiBaseURL returns the REST endpoint for host. GitHub Enterprise Server
+// serves the API under /api/v3 on the instance host.
+func (c *APIClient) apiBaseURL(host string) string {
+	if c.baseURL != "" {
+		return c.baseURL
+	}
+	if host == "" || host == DefaultHost {
+		return defaultAPIBaseURL
+	}
+	return fmt.Sprintf("https://%s/api/v3", host)
+}
+
+// graphQLURL returns the GraphQL endpoint for host, which GitHub
+// Enterprise Server serves at /api/graphql.
+func (c *APIClient) graphQLURL(host string) string {
+	if c.baseURL != "" {
+		return c.baseURL + "/graphql"
+	}
+	if host == "" || host == DefaultHost {
+		return defaultAPIBaseURL + "/graphql"
+	}
+	return fmt.Sprintf("https://%s/api/graphql", host)
+}
+
+func (c *APIClient) resolveToken(host string) (string, error) {
+	if c.token != "" {
+		return c.token, nil
+	}
+	if c.tokenFor == nil {
+		return "", nil
+	}
+	return c.tokenFor(host)
+}
+
+func apiError(status int, body []byte, authenticated bool) error {
+	var payload struct {
+		Message string `json:"message"`
+	}
+	msg := strings.TrimSpace(string(body))
+	if err := json.Unmarshal(body, &payload); err == nil && payload.Message != "" {
+		msg = payload.Message
+	}
+
+	err := fmt.Errorf("GitHub API returned %d: %s", status, msg)
+	switch status {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound:
+		if !authenticated {
+			return errors.Join(err, errors.New("no GitHub token found; set GITHUB_TOKEN or GH_TOKEN, or run 'gh auth login'"))
+		}
+	}
+	return err
+}
+
+type restPR struct {
+	Number   int     `json:"number"`
+	Title    string  `json:"title"`
+	State    string  `json:"state"`
+	Merged   bool    `json:"merged"`
+	MergedAt *string `json:"merged_at"`
+	HTMLURL  string  `json:"html_url"`
+	Head     restRef `json:"head"`
+	Base     restRef `json:"base"`
+}
+
+type restRef struct {
+	Ref  string    `json:"ref"`
+	Repo *restRepo `json:"repo"`
+	User *restUser `json:"user"`
+}
+
+type restRepo struct {
+	Name     string   `json:"name"`
+	FullName string   `json:"full_name"`
+	HTMLURL  string   `json:"html_url"`
+	Owner    restUser `json:"owner"`
+}
+
+type restUser struct {
+	Login string `json:"login"`
+}
+
+// state normalizes REST states to the uppercase values reported by gh.
+func (p restPR) state() string {
+	if p.Merged || p.MergedAt != nil {
+		return "MERGED"
+	}
+	return strings.ToUpper(p.State)
+}
+
+func (r *restRepo) toGH() *ghRepo {
+	if r == nil {
+		return nil
+	}
+	repo := &ghRepo{
+		Name:          r.Name,
+		NameWithOwner: r.FullName,
+		URL:           r.HTMLURL,
+	}
+	repo.Owner.Login = r.Owner.Login
+	return repo
+}
+
+func (u *restUser) toGHOwner() *ghRepoOwner {
+	if u == nil {
+		return nil
+	}
+	return &ghRepoOwner{Login: u.Login}
+}
@@ -0,0 +1,174 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestAPIServer(t *testing.T, path string, body string, status int) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			http.NotFound(w, r)
+			return
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer test-token" {
+			t.Errorf("expected bearer token, got %q", got)
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestAPIFetchPRMetadata(t *testing.T) {
+	body := `{
+		"number": 15,
+		"title": "Fix it",
+		"state": "open",
+		"merged": false,
+		"html_url": "https://github.com/octo/repo/pull/15",
+		"head": {
+			"ref": "feature",
+			"repo": {"name": "repo", "full_name": "forker/repo", "html_url": "https://github.com/forker/repo", "owner": {"login": "forker"}},
+			"user": {"login": "forker"}
+		},
+		"base": {"ref": "main"}
+	}`
+	server := newTestAPIServer(t, "/repos/octo/repo/pulls/15", body, http.StatusOK)
+	client := NewAPIClient(APIClientOptions{BaseURL: server.URL, Token: "test-token"})
+
+	meta, err := client.FetchPRMetadata(context.Background(), "https://github.com/octo/repo/pull/15")
+	if err != nil {
+		t.Fatalf("FetchPRMetadata: %v", err)
+	}
+	if meta.State != "OPEN" {
+		t.Fatalf("expected state OPEN, got %s", meta.State)
+	}
+	if meta.HeadRef != "feature" || meta.BaseRef != "main" {
+		t.Fatalf("unexpected refs: head=%s base=%s", meta.HeadRef, meta.BaseRef)
+	}
+	if meta.BaseRepo.CloneURL != "https://github.com/octo/repo.git" {
+		t.Fatalf("unexpected base clone URL %s", meta.BaseRepo.CloneURL)
+	}
+	if meta.HeadRepo.Owner != "forker" || meta.HeadRepo.CloneURL != "https://github.com/forker/repo.git" {
+		t.Fatalf("unexpected head repo: %+v", meta.HeadRepo)
+	}
+	if meta.HeadRepoMissing {
+		t.Fatalf("expected head repository to be present")
+	}
+}
+
+func TestAPIFetchPRMetadataAllowsMissingHeadRepository(t *testing.T) {
+	body := `{
+		"number": 15,
+		"title": "Fix it",
+		"state": "closed",
+		"merged": true,
+		"html_url": "https://github.com/octo/repo/pull/15",
+		"head": {"ref": "feature", "repo": null, "user": {"login": "forker"}},
+		"base": {"ref": "main"}
+	}`
+	server := newTestAPIServer(t, "/repos/octo/repo/pulls/15", body, http.StatusOK)
+	client := NewAPIClient(APIClientOptions{BaseURL: server.URL, Token: "test-token"})
+
+	meta, err := client.FetchPRMetadata(context.Background(), "https://github.com/octo/repo/pull/15")
+	if err != nil {
+		t.Fatalf("FetchPRMetadata: %v", err)
+	}
+	if meta.State != "MERGED" {
+		t.Fatalf("expected state MERGED, got %s", meta.State)
+	}
+	if !meta.HeadRepoMissing {
+		t.Fatalf("expected missing head repository to be recorded")
+	}
+	if meta.HeadRepo.Owner != "forker" || meta.HeadRepo.Name != "repo" {
+		t.Fatalf("unexpected fallback head repo: %+v", meta.HeadRepo)
+	}
+	if meta.HeadRepo.CloneURL != "" {
+		t.Fatalf("expected no clone URL for missing head repository, got %s", meta.HeadRepo.CloneURL)
+	}
+}
+
+func TestAPIFetchPRMetadataReportsAPIError(t *testing.T) {
+	server := newTestAPIServer(t, "/repos/octo/repo/pulls/15", `{"message": "Not Found"}`, http.StatusNotFound)
+	client := NewAPIClient(APIClientOptions{BaseURL: server.URL, Token: "test-token"})
+
+	_, err := client.FetchPRMetadata(context.Background(), "https://github.com/octo/repo/pull/15")
+	if err == nil {
+		t.Fatal("expected API error")
+	}
+	if !strings.Contains(err.Error(), "404") || !strings.Contains(err.Error(), "Not Found") {
+		t.Fatalf("expected status and message in error, got %v", err)
+	}
+}
+
+func TestTokenFromHostsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hosts.yml")
+	data := []byte("github.com:\n    user: octo\n    oauth_token: gho_abc\n    git_protocol: https\n")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("write hosts: %v", err)
+	}
+
+	token, err := tokenFromHostsFile(path, "github.com")
+	if err != nil {
+		t.Fatalf("tokenFromHostsFile: %v", err)
+	}
+	if token != "gho_abc" {
+		t.Fatalf("expected gho_abc, got %q", token)
+	}
+
+	token, err = tokenFromHostsFile(path, "other.example.com")
+	if err != nil {
+		t.Fatalf("tokenFromHostsFile: %v", err)
+	}
+	if token != "" {
+		t.Fatalf("expected no token for unknown host, got %q", token)
+	}
+}
+
+func TestResolveTokenPrefersEnvironment(t *testing.T) {
+	t.Setenv("GH_CONFIG_DIR", t.TempDir())
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GH_TOKEN", "env-token")
+
+	token, err := ResolveToken("github.com")
+	if err != nil {
+		t.Fatalf("ResolveToken: %v", err)
+	}
+	if token != "env-token" {
+		t.Fatalf("expected env-token, got %q", token)
+	}
+}
+
+func TestNewMetadataClientAutoSelection(t *testing.T) {
+	found := func(string) (string, error) { return "/usr/bin/gh", nil }
+	missing := func(string) (string, error) { return "", errors.New("not found") }
+
+	client, err := NewMetadataClient(BackendOptions{Backend: "auto", LookPath: found})
+	if err != nil {
+		t.Fatalf("NewMetadataClient: %v", err)
+	}
+	if _, ok := client.(*Client); !ok {
+		t.Fatalf("expected gh client when gh is installed, got %T", client)
+	}
+
+	client, err = NewMetadataClient(BackendOptions{Backend: "auto", LookPath: missing})
+	if err != nil {
+		t.Fatalf("NewMetadataClient: %v", err)
+	}
+	if _, ok := client.(*APIClient); !ok {
+		t.Fatalf("expected API client when gh is missing, got %T", client)
+	}
+
+	if _, err := NewMetadataClient(BackendOptions{Backend: "carrier-pigeon"}); err == nil {
+		t.Fatal("expected unsupported backend error")
+	}
+}
@@ -0,0 +1,74 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultHost is the public GitHub host.
+const DefaultHost = "github.com"
+
+// ResolveToken returns an API token for host from the environment or the gh
+// CLI hosts.yml. An empty token with a nil error means none was configured.
+// Enterprise hosts read GH_ENTERPRISE_TOKEN/GITHUB_ENTERPRISE_TOKEN, matching gh.
+func ResolveToken(host string) (string, error) {
+	envKeys := []string{"GITHUB_TOKEN", "GH_TOKEN"}
+	if host != "" && host != DefaultHost {
+		envKeys = []string{"GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN"}
+	}
+	for _, key := range envKeys {
+		if value := strings.TrimSpace(os.Getenv(key)); value != "" {
+			return value, nil
+		}
+	}
+
+	path, err := ghHostsPath()
+	if err != nil {
+		return "", err
+	}
+	return tokenFromHostsFile(path, host)
+}
+
+type ghHostEntry struct {
+	OAuthToken string `yaml:"oauth_token"`
+}
+
+func tokenFromHostsFile(path string, host string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		return "", fmt.Errorf("read gh hosts config: %w", err)
+	}
+
+	var hosts map[string]ghHostEntry
+	if err := yaml.Unmarshal(data, &hosts); err != nil {
+		return "", fmt.Errorf("parse gh hosts config: %w", err)
+	}
+	for name, entry := range hosts {
+		if strings.EqualFold(name, host) {
+			return strings.TrimSpace(entry.OAuthToken), nil
+		}
+	}
+	return "", nil
+}
+
+func ghHostsPath() (string, error) {
+	if dir := os.Getenv("GH_CONFIG_DIR"); dir != "" {
+		return filepath.Join(dir, "hosts.yml"), nil
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "gh", "hosts.yml"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("resolve home dir: %w", err)
+	}
+	return filepath.Join(home, ".config", "gh", "hosts.yml"), nil
+}
@@ -0,0 +1,64 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+)
+
+// Supported metadata backends.
+const (
+	BackendGH   = "gh"
+	BackendAPI  = "api"
+	BackendAuto = "auto"
+)
+
+// MetadataClient fetches pull request metadata from GitHub, and searches
+// and lists pull requests.
+type MetadataClient interface {
+	FetchPRMetadata(ctx context.Context, prURL string) (PRMetadata, error)
+	SearchPRs(ctx context.Context, opts SearchOptions) ([]PRRef, error)
+	ListPRs(ctx context.Context, repo Repository, limit int) ([]PRSummary, error)
+}
+
+// BackendOptions configures metadata backend selection.
+type BackendOptions struct {
+	Backend    string
+	Verbose    bool
+	Runner     Runner
+	HTTPClient *http.Client
+	// Hosts lists additional GitHub Enterprise Server hosts to accept.
+	Hosts []string
+	// LookPath locates the gh binary for auto selection; defaults to exec.LookPath.
+	LookPath func(file string) (string, error)
+}
+
+// NewMetadataClient returns the gh or API backend selected by opts.Backend.
+// The auto backend prefers gh when it is installed and otherwise uses the API.
+func NewMetadataClient(opts BackendOptions) (MetadataClient, error) {
+	backend := strings.ToLower(strings.TrimSpace(opts.Backend))
+	if backend == "" {
+		backend = BackendAuto
+	}
+	if backend == BackendAuto {
+		lookPath := opts.LookPath
+		if lookPath == nil {
+			lookPath = exec.LookPath
+		}
+		backend = BackendAPI
+		if _, err := lookPath("gh"); err == nil {
+			backend = BackendGH
+		}
+	}
+
+	switch backend {
+	case BackendGH:
+		return NewClient(ClientOptions{Verbose: opts.Verbose, Runner: opts.Runner, Hosts: opts.Hosts}), nil
+	case BackendAPI:
+		return NewAPIClient(APIClientOptions{HTTPClient: opts.HTTPClient, Hosts: opts.Hosts}), nil
+	default:
+		return nil, fmt.Errorf("unsupported github backend: %s", opts.Backend)
+	}
+}
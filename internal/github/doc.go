@@ -1,2 +1,3 @@
-// Package github parses PR URLs and fetches PR metadata via the gh CLI.
+// Package github parses PR URLs and fetches PR metadata via the gh CLI or
+// the GitHub API.
 package github
@@ -0,0 +1,233 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// DefaultListLimit is the number of PRs ListPRs returns when no limit is
+// given.
+const DefaultListLimit = 100
+
+// Check rollup states for PRSummary.Checks, as GitHub reports them.
+const (
+	ChecksSuccess = "SUCCESS"
+	ChecksFailure = "FAILURE"
+	ChecksPending = "PENDING"
+)
+
+// PRSummary describes an open pull request in a listing.
+type PRSummary struct {
+	Number int
+	Title  string
+	URL    string
+	Author string
+	Draft  bool
+	// ReviewDecision is APPROVED, CHANGES_REQUESTED, REVIEW_REQUIRED, or
+	// empty when the repository does not require reviews.
+	ReviewDecision string
+	// Checks is ChecksSuccess, ChecksFailure, ChecksPending, or empty when
+	// the head commit has no checks.
+	Checks string
+}
+
+// ListPRs returns repo's open pull requests, most recently updated first.
+func (c *Client) ListPRs(ctx context.Context, repo Repository, limit int) ([]PRSummary, error) {
+	if limit <= 0 {
+		limit = DefaultListLimit
+	}
+	args := []string{
+		"pr", "list",
+		"--repo", fmt.Sprintf("%s/%s/%s", repo.HostName(), repo.Owner, repo.Name),
+		"--state", "open",
+		"--limit", strconv.Itoa(limit),
+		"--json", "number,title,url,author,isDraft,reviewDecision,statusCheckRollup",
+	}
+	output, err := c.runner.Run(ctx, "gh", args...)
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, errors.New("gh CLI not found; install it from https://cli.github.com/")
+		}
+		return nil, fmt.Errorf("gh pr list failed: %w\n%s", err, strings.TrimSpace(string(output)))
+	}
+
+	var payload []ghPRListItem
+	if err := json.Unmarshal(output, &payload); err != nil {
+		return nil, fmt.Errorf("parse gh output: %w", err)
+	}
+	prs := make([]PRSummary, 0, len(payload))
+	for _, item := range payload {
+		prs = append(prs, PRSummary{
+			Number:         item.Number,
+			Title:          item.Title,
+			URL:            item.URL,
+			Author:         item.Author.Login,
+			Draft:          item.IsDraft,
+			ReviewDecision: item.ReviewDecision,
+			Checks:         rollupChecks(item.StatusCheckRollup),
+		})
+	}
+	return prs, nil
+}
+
+type ghPRListItem struct {
+	Number            int            `json:"number"`
+	Title             string         `json:"title"`
+	URL               string         `json:"url"`
+	Author            ghRepoOwner    `json:"author"`
+	IsDraft           bool           `json:"isDraft"`
+	ReviewDecision    string         `json:"reviewDecision"`
+	StatusCheckRollup []ghCheckState `json:"statusCheckRollup"`
+}
+
+// ghCheckState is a check run (status and conclusion) or a commit status
+// context (state).
+type ghCheckState struct {
+	Status     string `json:"status"`
+	Conclusion string `json:"conclusion"`
+	State      string `json:"state"`
+}
+
+// rollupChecks combines individual checks the way GitHub's rollup does:
+// any failure fails, then anything unfinished is pending.
+func rollupChecks(checks []ghCheckState) string {
+	if len(checks) == 0 {
+		return ""
+	}
+	pending := false
+	for _, check := range checks {
+		switch {
+		case check.State != "":
+			switch check.State {
+			case "FAILURE", "ERROR":
+				return ChecksFailure
+			case "PENDING", "EXPECTED":
+				pending = true
+			}
+		case check.Status != "COMPLETED":
+			pending = true
+		default:
+			switch check.Conclusion {
+			case "FAILURE", "CANCELLED", "TIMED_OUT", "ACTION_REQUIRED", "STARTUP_FAILURE":
+				return ChecksFailure
+			}
+		}
+	}
+	if pending {
+		return ChecksPending
+	}
+	return ChecksSuccess
+}
+
+const listPRsQuery = `query($owner: String!, $name: String!, $first: Int!) {
+  repository(owner: $owner, name: $name) {
+    pullRequests(states: OPEN, first: $first, orderBy: {field: UPDATED_AT, direction: DESC}) {
+      nodes {
+        number
+        title
+        url
+        isDraft
+        reviewDecision
+        author { login }
+        commits(last: 1) { nodes { commit { statusCheckRollup { state } } } }
+      }
+    }
+  }
+}`
+
+// ListPRs returns repo's open pull requests, most recently updated first.
+// It uses the GraphQL API, which requires a token.
+func (c *APIClient) ListPRs(ctx context.Context, repo Repository, limit int) ([]PRSummary, error) {
+	if limit <= 0 {
+		limit = DefaultListLimit
+	}
+	// GraphQL connections return at most 100 nodes.
+	limit = min(limit, 100)
+	request := map[string]any{
+		"query":     listPRsQuery,
+		"variables": map[string]any{"owner": repo.Owner, "name": repo.Name, "first": limit},
+	}
+
+	var payload graphQLPRList
+	host := repo.HostName()
+	if err := c.doJSON(ctx, host, http.MethodPost, c.graphQLURL(host), request, &payload); err != nil {
+		return nil, err
+	}
+	if len(payload.Errors) > 0 {
+		return nil, fmt.Errorf("GitHub GraphQL API error: %s", payload.Errors[0].Message)
+	}
+	if payload.Data.Repository == nil {
+		return nil, fmt.Errorf("repository %s/%s not found", repo.Owner, repo.Name)
+	}
+
+	nodes := payload.Data.Repository.PullRequests.Nodes
+	prs := make([]PRSummary, 0, len(nodes))
+	for _, node := range nodes {
+		pr := PRSummary{
+			Number:         node.Number,
+			Title:          node.Title,
+			URL:            node.URL,
+			Draft:          node.IsDraft,
+			ReviewDecision: node.ReviewDecision,
+		}
+		if node.Author != nil {
+			pr.Author = node.Author.Login
+		}
+		if len(node.Commits.Nodes) > 0 {
+			if rollup := node.Commits.Nodes[0].Commit.StatusCheckRollup; rollup != nil {
+				pr.Checks = rollupState(rollup.State)
+			}
+		}
+		prs = append(prs, pr)
+	}
+	return prs, nil
+}
+
+// rollupState maps a GraphQL StatusState to a Checks value.
+func rollupState(state string) string {
+	switch state {
+	case "SUCCESS":
+		return ChecksSuccess
+	case "FAILURE", "ERROR":
+		return ChecksFailure
+	case "PENDING", "EXPECTED":
+		return ChecksPending
+	default:
+		return ""
+	}
+}
+
+type graphQLPRList struct {
+	Data struct {
+		Repository *struct {
+			PullRequests struct {
+				Nodes []struct {
+					Number         int       `json:"number"`
+					Title          string    `json:"title"`
+					URL            string    `json:"url"`
+					IsDraft        bool      `json:"isDraft"`
+					ReviewDecision string    `json:"reviewDecision"`
+					Author         *restUser `json:"author"`
+					Commits        struct {
+						Nodes []struct {
+							Commit struct {
+								StatusCheckRollup *struct {
+									State string `json:"state"`
+								} `json:"statusCheckRollup"`
+							} `json:"commit"`
+						} `json:"nodes"`
+					} `json:"commits"`
+				} `json:"nodes"`
+			} `json:"pullRequests"`
+		} `json:"repository"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
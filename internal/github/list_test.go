@@ -0,0 +1,110 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestListPRsWithGH(t *testing.T) {
+	runner := &recordingRunner{output: `[
+		{"number": 12, "title": "Add feature", "url": "https://github.com/octo/repo/pull/12", "author": {"login": "hubot"},
+		 "isDraft": false, "reviewDecision": "APPROVED",
+		 "statusCheckRollup": [
+			{"__typename": "CheckRun", "status": "COMPLETED", "conclusion": "SUCCESS"},
+			{"__typename": "StatusContext", "state": "SUCCESS"}
+		 ]},
+		{"number": 11, "title": "WIP", "url": "https://github.com/octo/repo/pull/11", "author": {"login": "octocat"},
+		 "isDraft": true, "reviewDecision": "",
+		 "statusCheckRollup": [{"__typename": "CheckRun", "status": "IN_PROGRESS", "conclusion": ""}]},
+		{"number": 10, "title": "Broken", "url": "https://github.com/octo/repo/pull/10", "author": {"login": "octocat"},
+		 "isDraft": false, "reviewDecision": "CHANGES_REQUESTED",
+		 "statusCheckRollup": [
+			{"__typename": "CheckRun", "status": "IN_PROGRESS", "conclusion": ""},
+			{"__typename": "CheckRun", "status": "COMPLETED", "conclusion": "FAILURE"}
+		 ]},
+		{"number": 9, "title": "No CI", "url": "https://github.com/octo/repo/pull/9", "author": {"login": "octocat"},
+		 "isDraft": false, "reviewDecision": "", "statusCheckRollup": []}
+	]`}
+	client := NewClient(ClientOptions{Runner: runner})
+
+	prs, err := client.ListPRs(context.Background(), Repository{Host: "github.com", Owner: "octo", Name: "repo"}, 0)
+	if err != nil {
+		t.Fatalf("ListPRs: %v", err)
+	}
+	want := []PRSummary{
+		{Number: 12, Title: "Add feature", URL: "https://github.com/octo/repo/pull/12", Author: "hubot", ReviewDecision: "APPROVED", Checks: ChecksSuccess},
+		{Number: 11, Title: "WIP", URL: "https://github.com/octo/repo/pull/11", Author: "octocat", Draft: true, Checks: ChecksPending},
+		{Number: 10, Title: "Broken", URL: "https://github.com/octo/repo/pull/10", Author: "octocat", ReviewDecision: "CHANGES_REQUESTED", Checks: ChecksFailure},
+		{Number: 9, Title: "No CI", URL: "https://github.com/octo/repo/pull/9", Author: "octocat"},
+	}
+	if len(prs) != len(want) {
+		t.Fatalf("expected %d PRs, got %+v", len(want), prs)
+	}
+	for i := range want {
+		if prs[i] != want[i] {
+			t.Fatalf("PR %d: expected %+v, got %+v", i, want[i], prs[i])
+		}
+	}
+	if got := strings.Join(runner.args, " "); !strings.Contains(got, "--repo github.com/octo/repo") || !strings.Contains(got, "--limit 100") {
+		t.Fatalf("unexpected gh args %q", got)
+	}
+}
+
+func TestAPIListPRs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/graphql" || r.Method != http.MethodPost {
+			http.NotFound(w, r)
+			return
+		}
+		var request struct {
+			Variables map[string]any `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		if request.Variables["owner"] != "octo" || request.Variables["name"] != "repo" || request.Variables["first"] != float64(20) {
+			t.Errorf("unexpected variables %v", request.Variables)
+		}
+		_, _ = w.Write([]byte(`{"data": {"repository": {"pullRequests": {"nodes": [
+			{"number": 12, "title": "Add feature", "url": "https://github.com/octo/repo/pull/12", "isDraft": false,
+			 "reviewDecision": "REVIEW_REQUIRED", "author": {"login": "hubot"},
+			 "commits": {"nodes": [{"commit": {"statusCheckRollup": {"state": "ERROR"}}}]}},
+			{"number": 11, "title": "Ghost", "url": "https://github.com/octo/repo/pull/11", "isDraft": true,
+			 "reviewDecision": null, "author": null,
+			 "commits": {"nodes": [{"commit": {"statusCheckRollup": null}}]}}
+		]}}}}`))
+	}))
+	t.Cleanup(server.Close)
+	client := NewAPIClient(APIClientOptions{BaseURL: server.URL, Token: "test-token"})
+
+	prs, err := client.ListPRs(context.Background(), Repository{Owner: "octo", Name: "repo"}, 20)
+	if err != nil {
+		t.Fatalf("ListPRs: %v", err)
+	}
+	if len(prs) != 2 {
+		t.Fatalf("expected 2 PRs, got %+v", prs)
+	}
+	if prs[0] != (PRSummary{Number: 12, Title: "Add feature", URL: "https://github.com/octo/repo/pull/12", Author: "hubot", ReviewDecision: "REVIEW_REQUIRED", Checks: ChecksFailure}) {
+		t.Fatalf("unexpected first PR %+v", prs[0])
+	}
+	if prs[1] != (PRSummary{Number: 11, Title: "Ghost", URL: "https://github.com/octo/repo/pull/11", Draft: true}) {
+		t.Fatalf("unexpected second PR %+v", prs[1])
+	}
+}
+
+func TestAPIListPRsReportsGraphQLErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"data": {"repository": null}, "errors": [{"message": "Could not resolve to a Repository"}]}`))
+	}))
+	t.Cleanup(server.Close)
+	client := NewAPIClient(APIClientOptions{BaseURL: server.URL, Token: "test-token"})
+
+	_, err := client.ListPRs(context.Background(), Repository{Owner: "octo", Name: "missing"}, 0)
+	if err == nil || !strings.Contains(err.Error(), "Could not resolve") {
+		t.Fatalf("expected the GraphQL error, got %v", err)
+	}
+}
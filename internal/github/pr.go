@@ -11,21 +11,34 @@ import (
 	"strings"
 )
 
-// PRRef identifies a pull request by repository and number.
+// PRRef identifies a pull request by host, repository, and number.
 type PRRef struct {
+	Host   string
 	Owner  string
 	Repo   string
 	Number int
+	// Commit is set when the reference points at a specific PR commit
+	// (e.g. a /pull/N/commits/<sha> link).
+	Commit string
 }
 
 // Repository identifies a GitHub repository and clone URL.
 type Repository struct {
+	Host     string
 	Owner    string
 	Name     string
 	URL      string
 	CloneURL string
 }
 
+// HostName returns the repository host, defaulting to github.com.
+func (r Repository) HostName() string {
+	if r.Host == "" {
+		return DefaultHost
+	}
+	return r.Host
+}
+
 // PRMetadata contains pull request details required for worktree setup.
 type PRMetadata struct {
 	Number   int
@@ -45,12 +58,15 @@ type PRMetadata struct {
 type Client struct {
 	runner  Runner
 	verbose bool
+	hosts   []string
 }
 
 // ClientOptions configures a GitHub metadata client.
 type ClientOptions struct {
 	Verbose bool
 	Runner  Runner
+	// Hosts lists additional GitHub Enterprise Server hosts to accept.
+	Hosts []string
 }
 
 // Runner executes external commands for metadata retrieval.
@@ -73,33 +89,39 @@ func NewClient(opts ClientOptions) *Client {
 	if runner == nil {
 		runner = ExecRunner{}
 	}
-	return &Client{runner: runner, verbose: opts.Verbose}
+	return &Client{runner: runner, verbose: opts.Verbose, hosts: opts.Hosts}
 }
 
-// ParsePRURL parses a GitHub pull request URL into owner, repo, and number.
+// ParsePRURL parses a github.com pull request URL into owner, repo, and number.
 func ParsePRURL(prURL string) (PRRef, error) {
+	return ParsePRURLForHosts(prURL, nil)
+}
+
+// ParsePRURLForHosts parses a pull request URL, additionally accepting the
+// given GitHub Enterprise Server hosts. Besides the canonical
+// /owner/repo/pull/N form it accepts URLs without a scheme, query strings,
+// anchors, and the files, commits, commits/<sha>, and checks sub-pages.
+func ParsePRURLForHosts(prURL string, hosts []string) (PRRef, error) {
+	prURL = strings.TrimSpace(prURL)
+	if !strings.Contains(prURL, "://") {
+		prURL = "https://" + prURL
+	}
 	parsed, err := url.Parse(prURL)
 	if err != nil {
 		return PRRef{}, fmt.Errorf("invalid URL: %w", err)
 	}
 
-	host := strings.ToLower(parsed.Host)
-	if host == "" {
+	if parsed.Host == "" {
 		return PRRef{}, errors.New("missing URL host")
 	}
-	if host != "github.com" && !strings.HasSuffix(host, ".github.com") {
-		return PRRef{}, fmt.Errorf("unsupported host: %s", parsed.Host)
+	host, ok := allowedHost(parsed.Host, hosts)
+	if !ok {
+		return PRRef{}, fmt.Errorf("unsupported host: %s (add it to github_hosts for GitHub Enterprise)", parsed.Host)
 	}
 
-	cleanPath := strings.TrimSuffix(parsed.Path, "/")
+	cleanPath := strings.Trim(parsed.Path, "/")
 	parts := strings.Split(cleanPath, "/")
-	if len(parts) < 5 {
-		return PRRef{}, errors.New("expected /owner/repo/pull/number")
-	}
-	if parts[0] == "" {
-		parts = parts[1:]
-	}
-	if len(parts) < 4 {
+	if len(parts) < 4 || parts[0] == "" || parts[1] == "" {
 		return PRRef{}, errors.New("expected /owner/repo/pull/number")
 	}
 
@@ -114,18 +136,143 @@ func ParsePRURL(prURL string) (PRRef, error) {
 		return PRRef{}, errors.New("invalid pull request number")
 	}
 
-	return PRRef{Owner: owner, Repo: repo, Number: number}, nil
+	commit, err := commitFromSubPage(parts[4:])
+	if err != nil {
+		return PRRef{}, err
+	}
+
+	return PRRef{Host: host, Owner: owner, Repo: repo, Number: number, Commit: commit}, nil
+}
+
+// commitFromSubPage validates the path segments after /pull/N and returns
+// the commit SHA for /commits/<sha> links.
+func commitFromSubPage(parts []string) (string, error) {
+	if len(parts) == 0 {
+		return "", nil
+	}
+	switch parts[0] {
+	case "files", "checks":
+		if len(parts) == 1 || (parts[0] == "files" && len(parts) == 2) {
+			return "", nil
+		}
+	case "commits":
+		if len(parts) == 1 {
+			return "", nil
+		}
+		if len(parts) == 2 {
+			if !isCommitSHA(parts[1]) {
+				return "", fmt.Errorf("invalid commit SHA %q", parts[1])
+			}
+			return strings.ToLower(parts[1]), nil
+		}
+	}
+	return "", fmt.Errorf("unsupported pull request page: %s", strings.Join(parts, "/"))
+}
+
+func isCommitSHA(value string) bool {
+	if len(value) < 7 || len(value) > 64 {
+		return false
+	}
+	for _, r := range strings.ToLower(value) {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// ParsePRReference parses a PR URL or an "owner/repo#123" shorthand. The
+// shorthand may be prefixed with an allowed host ("host/owner/repo#123").
+func ParsePRReference(value string, hosts []string) (PRRef, error) {
+	value = strings.TrimSpace(value)
+	if strings.Contains(value, "://") || strings.Contains(value, "/pull/") {
+		return ParsePRURLForHosts(value, hosts)
+	}
+
+	repoPart, numberPart, ok := strings.Cut(value, "#")
+	if !ok {
+		return PRRef{}, fmt.Errorf("invalid PR reference %q (expected URL, owner/repo#123, or #123)", value)
+	}
+	number, ok := ParsePRNumber(numberPart)
+	if !ok {
+		return PRRef{}, errors.New("invalid pull request number")
+	}
+
+	parts := strings.Split(strings.Trim(repoPart, "/"), "/")
+	host := DefaultHost
+	if len(parts) == 3 {
+		allowed, ok := allowedHost(parts[0], hosts)
+		if !ok {
+			return PRRef{}, fmt.Errorf("unsupported host: %s (add it to github_hosts for GitHub Enterprise)", parts[0])
+		}
+		host = allowed
+		parts = parts[1:]
+	}
+	if len(parts) != 2 || !validRepoName(parts[0]) || !validRepoName(parts[1]) {
+		return PRRef{}, fmt.Errorf("invalid repository %q (expected owner/repo)", repoPart)
+	}
+
+	return PRRef{Host: host, Owner: parts[0], Repo: parts[1], Number: number}, nil
+}
+
+// ParsePRNumber parses a bare "123" or "#123" pull request number.
+func ParsePRNumber(value string) (int, bool) {
+	number, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(value), "#"))
+	if err != nil || number <= 0 {
+		return 0, false
+	}
+	return number, true
+}
+
+func validRepoName(name string) bool {
+	return name != "" && !strings.ContainsAny(name, " \t#?")
+}
+
+// URL returns the canonical web URL for the pull request.
+func (r PRRef) URL() string {
+	return fmt.Sprintf("https://%s/%s/%s/pull/%d", r.hostName(), r.Owner, r.Repo, r.Number)
+}
+
+func (r PRRef) hostName() string {
+	if r.Host == "" {
+		return DefaultHost
+	}
+	return r.Host
+}
+
+// allowedHost normalizes host and reports whether it is github.com, a
+// github.com subdomain, or one of the configured enterprise hosts.
+func allowedHost(host string, hosts []string) (string, bool) {
+	host = NormalizeHost(host)
+	if host == DefaultHost || strings.HasSuffix(host, "."+DefaultHost) {
+		return DefaultHost, true
+	}
+	for _, allowed := range hosts {
+		if NormalizeHost(allowed) == host {
+			return host, true
+		}
+	}
+	return "", false
+}
+
+// NormalizeHost lowercases host and strips any scheme or trailing slash.
+func NormalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	host = strings.TrimPrefix(host, "https://")
+	host = strings.TrimPrefix(host, "http://")
+	return strings.TrimSuffix(host, "/")
 }
 
 // FetchPRMetadata loads pull request metadata needed to resolve worktrees.
 func (c *Client) FetchPRMetadata(ctx context.Context, prURL string) (PRMetadata, error) {
-	ref, err := ParsePRURL(prURL)
+	ref, err := ParsePRURLForHosts(prURL, c.hosts)
 	if err != nil {
 		return PRMetadata{}, err
 	}
 
 	args := []string{
-		"pr", "view", prURL,
+		"pr", "view", strconv.Itoa(ref.Number),
+		"--repo", fmt.Sprintf("%s/%s/%s", ref.hostName(), ref.Owner, ref.Repo),
 		"--json", "number,title,state,url,headRefName,baseRefName,headRepository,headRepositoryOwner",
 	}
 
@@ -142,13 +289,7 @@ func (c *Client) FetchPRMetadata(ctx context.Context, prURL string) (PRMetadata,
 		return PRMetadata{}, fmt.Errorf("parse gh output: %w", err)
 	}
 
-	baseRepo := Repository{
-		Owner:    ref.Owner,
-		Name:     ref.Repo,
-		URL:      fmt.Sprintf("https://github.com/%s/%s", ref.Owner, ref.Repo),
-		CloneURL: fmt.Sprintf("https://github.com/%s/%s.git", ref.Owner, ref.Repo),
-	}
-
+	baseRepo := baseRepository(ref)
 	headRepo, headRepoMissing, err := repoFromHeadPayload(payload.HeadRepository, payload.HeadRepositoryOwner, ref)
 	if err != nil {
 		return PRMetadata{}, fmt.Errorf("head repository: %w", err)
@@ -192,6 +333,16 @@ type ghRepoOwner struct {
 	Name  string `json:"name"`
 }
 
+func baseRepository(ref PRRef) Repository {
+	return Repository{
+		Host:     ref.hostName(),
+		Owner:    ref.Owner,
+		Name:     ref.Repo,
+		URL:      fmt.Sprintf("https://%s/%s/%s", ref.hostName(), ref.Owner, ref.Repo),
+		CloneURL: fmt.Sprintf("https://%s/%s/%s.git", ref.hostName(), ref.Owner, ref.Repo),
+	}
+}
+
 func repoFromHeadPayload(repo *ghRepo, owner *ghRepoOwner, ref PRRef) (Repository, bool, error) {
 	if repo == nil {
 		fallbackOwner := ref.Owner
@@ -199,6 +350,7 @@ func repoFromHeadPayload(repo *ghRepo, owner *ghRepoOwner, ref PRRef) (Repositor
 			fallbackOwner = owner.Login
 		}
 		return Repository{
+			Host:  ref.hostName(),
 			Owner: fallbackOwner,
 			Name:  ref.Repo,
 		}, true, nil
@@ -224,11 +376,12 @@ func repoFromHeadPayload(repo *ghRepo, owner *ghRepoOwner, ref PRRef) (Repositor
 
 	cloneURL := repo.URL
 	if cloneURL == "" {
-		cloneURL = fmt.Sprintf("https://github.com/%s/%s", ownerLogin, name)
+		cloneURL = fmt.Sprintf("https://%s/%s/%s", ref.hostName(), ownerLogin, name)
 	}
 	cloneURL = ensureGitSuffix(cloneURL)
 
 	return Repository{
+		Host:     ref.hostName(),
 		Owner:    ownerLogin,
 		Name:     name,
 		URL:      repo.URL,
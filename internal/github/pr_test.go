@@ -3,6 +3,7 @@ package github
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -128,3 +129,196 @@ func TestFetchPRMetadataRejectsMalformedHeadRepository(t *testing.T) {
 		t.Fatalf("expected descriptive error, got %q", got)
 	}
 }
+
+func TestParsePRURLForEnterpriseHosts(t *testing.T) {
+	hosts := []string{"ghe.example.com"}
+
+	ref, err := ParsePRURLForHosts("https://GHE.example.com/octo/repo/pull/7", hosts)
+	if err != nil {
+		t.Fatalf("expected enterprise host to parse: %v", err)
+	}
+	if ref.Host != "ghe.example.com" || ref.Owner != "octo" || ref.Repo != "repo" || ref.Number != 7 {
+		t.Fatalf("unexpected parse: %+v", ref)
+	}
+	if ref.URL() != "https://ghe.example.com/octo/repo/pull/7" {
+		t.Fatalf("unexpected canonical URL: %s", ref.URL())
+	}
+
+	ref, err = ParsePRURLForHosts("https://www.github.com/octo/repo/pull/7", hosts)
+	if err != nil {
+		t.Fatalf("expected github.com subdomain to parse: %v", err)
+	}
+	if ref.Host != "github.com" {
+		t.Fatalf("expected github.com host, got %s", ref.Host)
+	}
+
+	if _, err := ParsePRURL("https://ghe.example.com/octo/repo/pull/7"); err == nil {
+		t.Fatalf("expected enterprise host to be rejected without allow-list")
+	}
+}
+
+type recordingRunner struct {
+	output string
+	args   []string
+}
+
+func (r *recordingRunner) Run(_ context.Context, _ string, args ...string) ([]byte, error) {
+	r.args = args
+	return []byte(r.output), nil
+}
+
+func TestFetchPRMetadataUsesEnterpriseHost(t *testing.T) {
+	runner := &recordingRunner{output: `{
+		"number": 7,
+		"title": "Fix it",
+		"state": "OPEN",
+		"url": "https://ghe.example.com/octo/repo/pull/7",
+		"headRefName": "feature",
+		"baseRefName": "main",
+		"headRepository": {"name": "repo", "nameWithOwner": "octo/repo", "url": "", "owner": {"login": "octo"}},
+		"headRepositoryOwner": {"login": "octo"}
+	}`}
+	client := NewClient(ClientOptions{Runner: runner, Hosts: []string{"ghe.example.com"}})
+
+	meta, err := client.FetchPRMetadata(context.Background(), "https://ghe.example.com/octo/repo/pull/7")
+	if err != nil {
+		t.Fatalf("FetchPRMetadata: %v", err)
+	}
+	if meta.BaseRepo.CloneURL != "https://ghe.example.com/octo/repo.git" {
+		t.Fatalf("unexpected base clone URL %s", meta.BaseRepo.CloneURL)
+	}
+	if meta.HeadRepo.CloneURL != "https://ghe.example.com/octo/repo.git" {
+		t.Fatalf("unexpected head clone URL %s", meta.HeadRepo.CloneURL)
+	}
+	if meta.BaseRepo.Host != "ghe.example.com" || meta.HeadRepo.Host != "ghe.example.com" {
+		t.Fatalf("expected host on repositories, got base=%s head=%s", meta.BaseRepo.Host, meta.HeadRepo.Host)
+	}
+	if got := strings.Join(runner.args, " "); !strings.Contains(got, "--repo ghe.example.com/octo/repo") {
+		t.Fatalf("expected gh to target enterprise host, got args %q", got)
+	}
+}
+
+func TestParsePRReference(t *testing.T) {
+	hosts := []string{"ghe.example.com"}
+	cases := []struct {
+		name  string
+		input string
+		want  PRRef
+		ok    bool
+	}{
+		{
+			name:  "url",
+			input: "https://github.com/octo/repo/pull/15",
+			want:  PRRef{Host: "github.com", Owner: "octo", Repo: "repo", Number: 15},
+			ok:    true,
+		},
+		{
+			name:  "shorthand",
+			input: "octo/repo#15",
+			want:  PRRef{Host: "github.com", Owner: "octo", Repo: "repo", Number: 15},
+			ok:    true,
+		},
+		{
+			name:  "enterprise shorthand",
+			input: "ghe.example.com/octo/repo#15",
+			want:  PRRef{Host: "ghe.example.com", Owner: "octo", Repo: "repo", Number: 15},
+			ok:    true,
+		},
+		{
+			name:  "unknown host shorthand",
+			input: "gitlab.com/octo/repo#15",
+		},
+		{
+			name:  "missing number",
+			input: "octo/repo#",
+		},
+		{
+			name:  "missing repo",
+			input: "octo#15",
+		},
+		{
+			name:  "no separator",
+			input: "octo/repo",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ref, err := ParsePRReference(tc.input, hosts)
+			if tc.ok {
+				if err != nil {
+					t.Fatalf("expected success: %v", err)
+				}
+				if ref != tc.want {
+					t.Fatalf("expected %+v, got %+v", tc.want, ref)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected error for %s", tc.input)
+			}
+		})
+	}
+}
+
+func TestParsePRNumber(t *testing.T) {
+	if n, ok := ParsePRNumber("#42"); !ok || n != 42 {
+		t.Fatalf("expected #42 to parse, got %d %v", n, ok)
+	}
+	if n, ok := ParsePRNumber("42"); !ok || n != 42 {
+		t.Fatalf("expected 42 to parse, got %d %v", n, ok)
+	}
+	for _, input := range []string{"", "#", "0", "-1", "abc"} {
+		if _, ok := ParsePRNumber(input); ok {
+			t.Fatalf("expected %q to be rejected", input)
+		}
+	}
+}
+
+func TestParsePRURLSubPages(t *testing.T) {
+	cases := []struct {
+		name   string
+		input  string
+		number int
+		commit string
+		ok     bool
+	}{
+		{name: "files", input: "https://github.com/octo/repo/pull/15/files", number: 15, ok: true},
+		{name: "files with anchor", input: "https://github.com/octo/repo/pull/15/files#diff-abc123R10", number: 15, ok: true},
+		{name: "files commit range", input: "https://github.com/octo/repo/pull/15/files/abc1234..def5678", number: 15, ok: true},
+		{name: "commits", input: "https://github.com/octo/repo/pull/15/commits", number: 15, ok: true},
+		{
+			name:   "single commit",
+			input:  "https://github.com/octo/repo/pull/15/commits/ABC1234DEF5678ABC1234DEF5678ABC1234DEF56",
+			number: 15,
+			commit: "abc1234def5678abc1234def5678abc1234def56",
+			ok:     true,
+		},
+		{name: "checks", input: "https://github.com/octo/repo/pull/15/checks", number: 15, ok: true},
+		{name: "query", input: "https://github.com/octo/repo/pull/15?notification_referrer_id=NT_abc", number: 15, ok: true},
+		{name: "anchor", input: "https://github.com/octo/repo/pull/15#issuecomment-1", number: 15, ok: true},
+		{name: "no scheme", input: "github.com/octo/repo/pull/15", number: 15, ok: true},
+		{name: "http", input: "http://github.com/octo/repo/pull/15/", number: 15, ok: true},
+		{name: "invalid commit", input: "https://github.com/octo/repo/pull/15/commits/not-a-sha"},
+		{name: "unknown page", input: "https://github.com/octo/repo/pull/15/reviews"},
+		{name: "checks extra segment", input: "https://github.com/octo/repo/pull/15/checks/run"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ref, err := ParsePRURL(tc.input)
+			if tc.ok {
+				if err != nil {
+					t.Fatalf("expected success: %v", err)
+				}
+				if ref.Owner != "octo" || ref.Repo != "repo" || ref.Number != tc.number || ref.Commit != tc.commit {
+					t.Fatalf("unexpected parse: %+v", ref)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected error for %s", tc.input)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultSearchLimit is the number of PRs SearchPRs returns when
+	// SearchOptions.Limit is unset.
+	DefaultSearchLimit = 30
+	searchPageSize     = 100
+)
+
+// SearchOptions selects open pull requests. Set filters are combined; at
+// least one is required.
+type SearchOptions struct {
+	// Host is the GitHub host to search; defaults to github.com.
+	Host string
+	// ReviewRequested is a user ("@me" for the authenticated user) whose
+	// review is requested.
+	ReviewRequested string
+	// Team is an "org/team" whose review is requested.
+	Team   string
+	Author string
+	Labels []string
+	// Repo restricts results to "owner/repo".
+	Repo string
+	// Query is a raw GitHub search query added to the filters.
+	Query string
+	Limit int
+}
+
+// query returns the GitHub search query for opts.
+func (o SearchOptions) query() (string, error) {
+	var filters []string
+	if o.ReviewRequested != "" {
+		filters = append(filters, "review-requested:"+o.ReviewRequested)
+	}
+	if o.Team != "" {
+		filters = append(filters, "team-review-requested:"+o.Team)
+	}
+	if o.Author != "" {
+		filters = append(filters, "author:"+o.Author)
+	}
+	for _, label := range o.Labels {
+		if strings.ContainsAny(label, " \t") {
+			label = strconv.Quote(label)
+		}
+		filters = append(filters, "label:"+label)
+	}
+	if o.Repo != "" {
+		filters = append(filters, "repo:"+o.Repo)
+	}
+	if query := strings.TrimSpace(o.Query); query != "" {
+		filters = append(filters, query)
+	}
+	if len(filters) == 0 {
+		return "", errors.New("search needs at least one filter")
+	}
+	return strings.Join(append([]string{"is:pr", "is:open", "archived:false"}, filters...), " "), nil
+}
+
+type searchResponse struct {
+	Items []struct {
+		HTMLURL string `json:"html_url"`
+	} `json:"items"`
+}
+
+// getJSONFunc fetches a REST API path from host into out.
+type getJSONFunc func(ctx context.Context, host string, path string, out any) error
+
+// searchPRs pages through the issue search API until opts.Limit PRs are
+// found or the results run out.
+func searchPRs(ctx context.Context, opts SearchOptions, hosts []string, get getJSONFunc) ([]PRRef, error) {
+	query, err := opts.query()
+	if err != nil {
+		return nil, err
+	}
+	host := DefaultHost
+	if opts.Host != "" {
+		allowed, ok := allowedHost(opts.Host, hosts)
+		if !ok {
+			return nil, fmt.Errorf("unsupported host: %s (add it to github_hosts for GitHub Enterprise)", opts.Host)
+		}
+		host = allowed
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+
+	var refs []PRRef
+	for page := 1; len(refs) < limit; page++ {
+		perPage := min(limit-len(refs), searchPageSize)
+		params := url.Values{}
+		params.Set("q", query)
+		params.Set("per_page", strconv.Itoa(perPage))
+		params.Set("page", strconv.Itoa(page))
+
+		var payload searchResponse
+		if err := get(ctx, host, "/search/issues?"+params.Encode(), &payload); err != nil {
+			return nil, err
+		}
+		for _, item := range payload.Items {
+			ref, err := ParsePRURLForHosts(item.HTMLURL, hosts)
+			if err != nil {
+				return nil, fmt.Errorf("search result %s: %w", item.HTMLURL, err)
+			}
+			refs = append(refs, ref)
+		}
+		if len(payload.Items) < perPage {
+			break
+		}
+	}
+	return refs, nil
+}
+
+// SearchPRs returns open pull requests matching opts.
+func (c *APIClient) SearchPRs(ctx context.Context, opts SearchOptions) ([]PRRef, error) {
+	return searchPRs(ctx, opts, c.hosts, c.getJSON)
+}
+
+// SearchPRs returns open pull requests matching opts, using gh api so the
+// query and results match the REST backend.
+func (c *Client) SearchPRs(ctx context.Context, opts SearchOptions) ([]PRRef, error) {
+	return searchPRs(ctx, opts, c.hosts, c.apiJSON)
+}
+
+// apiJSON runs "gh api" for a GET request to path on host.
+func (c *Client) apiJSON(ctx context.Context, host string, path string, out any) error {
+	output, err := c.runner.Run(ctx, "gh", "api", "--hostname", host, strings.TrimPrefix(path, "/"))
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return errors.New("gh CLI not found; install it from https://cli.github.com/")
+		}
+		return fmt.Errorf("gh api failed: %w\n%s", err, strings.TrimSpace(string(output)))
+	}
+	if err := json.Unmarshal(output, out); err != nil {
+		return fmt.Errorf("parse gh output: %w", err)
+	}
+	return nil
+}
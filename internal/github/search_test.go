@@ -0,0 +1,104 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestSearchOptionsQuery(t *testing.T) {
+	query, err := SearchOptions{
+		ReviewRequested: "@me",
+		Team:            "octo/reviewers",
+		Author:          "hubot",
+		Labels:          []string{"bug", "needs review"},
+		Repo:            "octo/repo",
+		Query:           "draft:false",
+	}.query()
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	want := `is:pr is:open archived:false review-requested:@me team-review-requested:octo/reviewers author:hubot label:bug label:"needs review" repo:octo/repo draft:false`
+	if query != want {
+		t.Fatalf("unexpected query:\n got %s\nwant %s", query, want)
+	}
+
+	if _, err := (SearchOptions{}).query(); err == nil {
+		t.Fatalf("expected an error without filters")
+	}
+}
+
+func TestAPISearchPRsPages(t *testing.T) {
+	var pages []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/search/issues" {
+			http.NotFound(w, r)
+			return
+		}
+		if got := r.URL.Query().Get("q"); got != "is:pr is:open archived:false review-requested:@me" {
+			t.Errorf("unexpected query %q", got)
+		}
+		page := r.URL.Query().Get("page")
+		pages = append(pages, page+"/"+r.URL.Query().Get("per_page"))
+		var items []string
+		count := 100
+		if page == "2" {
+			count = 20
+		}
+		for i := range count {
+			number := i + 1
+			if page == "2" {
+				number += 100
+			}
+			items = append(items, fmt.Sprintf(`{"html_url": "https://github.com/octo/repo/pull/%d"}`, number))
+		}
+		_, _ = fmt.Fprintf(w, `{"items": [%s]}`, strings.Join(items, ","))
+	}))
+	t.Cleanup(server.Close)
+	client := NewAPIClient(APIClientOptions{BaseURL: server.URL, Token: "test-token"})
+
+	refs, err := client.SearchPRs(context.Background(), SearchOptions{ReviewRequested: "@me", Limit: 150})
+	if err != nil {
+		t.Fatalf("SearchPRs: %v", err)
+	}
+	if len(refs) != 120 {
+		t.Fatalf("expected 120 PRs, got %d", len(refs))
+	}
+	if refs[0] != (PRRef{Host: DefaultHost, Owner: "octo", Repo: "repo", Number: 1}) || refs[119].Number != 120 {
+		t.Fatalf("unexpected refs: first %+v, last %+v", refs[0], refs[119])
+	}
+	if strings.Join(pages, ",") != "1/100,2/50" {
+		t.Fatalf("unexpected pages requested: %v", pages)
+	}
+}
+
+func TestSearchPRsUsesGHAPIOnEnterpriseHost(t *testing.T) {
+	runner := &recordingRunner{output: `{"items": [{"html_url": "https://ghe.example.com/octo/repo/pull/7"}]}`}
+	client := NewClient(ClientOptions{Runner: runner, Hosts: []string{"ghe.example.com"}})
+
+	refs, err := client.SearchPRs(context.Background(), SearchOptions{Host: "ghe.example.com", Labels: []string{"review"}})
+	if err != nil {
+		t.Fatalf("SearchPRs: %v", err)
+	}
+	if len(refs) != 1 || refs[0].Host != "ghe.example.com" || refs[0].Number != 7 {
+		t.Fatalf("unexpected refs: %+v", refs)
+	}
+	if len(runner.args) != 4 || runner.args[0] != "api" || runner.args[2] != "ghe.example.com" {
+		t.Fatalf("unexpected gh args %q", runner.args)
+	}
+	endpoint, err := url.Parse(runner.args[3])
+	if err != nil {
+		t.Fatalf("parse endpoint: %v", err)
+	}
+	if endpoint.Path != "search/issues" || endpoint.Query().Get("q") != "is:pr is:open archived:false label:review" {
+		t.Fatalf("unexpected endpoint %s", runner.args[3])
+	}
+
+	if _, err := client.SearchPRs(context.Background(), SearchOptions{Host: "gitlab.com", Author: "me"}); err == nil {
+		t.Fatalf("expected an unknown host to be rejected")
+	}
+}
@@ -0,0 +1,58 @@
+package terminal
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// CommandRunner launches terminal programs. It is injectable so openers can
+// be tested without starting real processes.
+type CommandRunner interface {
+	// Run executes a command and waits for it to finish.
+	Run(name string, args ...string) error
+	// Start launches a command without waiting for it to exit.
+	Start(name string, args ...string) error
+	// RunAttached runs a command in dir connected to prt's own terminal and
+	// waits for it to exit.
+	RunAttached(dir string, name string, args ...string) error
+}
+
+// ExecRunner runs commands via os/exec.
+type ExecRunner struct{}
+
+// Run executes a command and includes its output in any error.
+func (ExecRunner) Run(name string, args ...string) error {
+	output, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		msg := strings.TrimSpace(string(output))
+		if msg == "" {
+			return fmt.Errorf("%s failed: %w", name, err)
+		}
+		return fmt.Errorf("%s failed: %s", name, msg)
+	}
+	return nil
+}
+
+// Start launches a command in the background and releases it.
+func (ExecRunner) Start(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("start %s: %w", name, err)
+	}
+	return cmd.Process.Release()
+}
+
+// RunAttached runs a command in dir with prt's standard streams.
+func (ExecRunner) RunAttached(dir string, name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s failed: %w", name, err)
+	}
+	return nil
+}
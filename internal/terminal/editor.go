@@ -0,0 +1,168 @@
+package terminal
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// EditorConfig controls editor opener selection.
+type EditorConfig struct {
+	// Editor names an editor ("code", "cursor", "idea", "zed", "nvim", ...)
+	// or a command line. "" or "auto" uses $VISUAL, then $EDITOR, then the
+	// first of code, cursor, or zed found on PATH.
+	Editor string
+	// File, relative to the opened path, is opened alongside the folder.
+	File string
+}
+
+type editorKind int
+
+const (
+	// editorTUI runs in prt's own terminal until it exits.
+	editorTUI editorKind = iota
+	editorVSCode
+	editorJetBrains
+	editorZed
+)
+
+// editorKinds maps launcher names to how they accept a folder and file.
+var editorKinds = map[string]editorKind{
+	"code":           editorVSCode,
+	"code-insiders":  editorVSCode,
+	"codium":         editorVSCode,
+	"cursor":         editorVSCode,
+	"windsurf":       editorVSCode,
+	"idea":           editorJetBrains,
+	"goland":         editorJetBrains,
+	"pycharm":        editorJetBrains,
+	"webstorm":       editorJetBrains,
+	"clion":          editorJetBrains,
+	"rider":          editorJetBrains,
+	"rubymine":       editorJetBrains,
+	"phpstorm":       editorJetBrains,
+	"rustrover":      editorJetBrains,
+	"datagrip":       editorJetBrains,
+	"android-studio": editorJetBrains,
+	"zed":            editorZed,
+	"zeditor":        editorZed,
+}
+
+// DetectEditor returns an opener that opens a worktree in an editor.
+func DetectEditor(cfg EditorConfig) (TabOpener, error) {
+	return detectEditor(cfg, os.Getenv, exec.LookPath, ExecRunner{})
+}
+
+func detectEditor(cfg EditorConfig, getenv func(string) string, lookPath func(string) (string, error), runner CommandRunner) (TabOpener, error) {
+	command := strings.TrimSpace(cfg.Editor)
+	if command == "" || strings.EqualFold(command, "auto") {
+		command = firstNonEmpty(getenv("VISUAL"), getenv("EDITOR"))
+	}
+	if command == "" {
+		for _, candidate := range []string{"code", "cursor", "zed"} {
+			if _, err := lookPath(candidate); err == nil {
+				command = candidate
+				break
+			}
+		}
+	}
+	if command == "" {
+		return nil, errors.New("no editor found (set editor in the config, $VISUAL, or $EDITOR)")
+	}
+
+	fields := strings.Fields(command)
+	kind := editorKinds[strings.TrimSuffix(strings.ToLower(filepath.Base(fields[0])), ".exe")]
+	args := fields[1:]
+	if kind != editorTUI {
+		// $EDITOR values like "code --wait" are meant for git; prt should
+		// not block until the window closes.
+		args = withoutWaitFlags(args)
+	}
+	return editorOpener{runner: runner, name: fields[0], args: args, kind: kind, file: cfg.File}, nil
+}
+
+type editorOpener struct {
+	runner CommandRunner
+	name   string
+	args   []string
+	kind   editorKind
+	file   string
+}
+
+// Open opens path in the editor, along with o.file when set.
+func (o editorOpener) Open(path string) error {
+	args := append([]string{}, o.args...)
+	var file string
+	if o.file != "" {
+		file = filepath.Join(path, o.file)
+	}
+
+	switch o.kind {
+	case editorVSCode:
+		args = append(args, path)
+		if file != "" {
+			args = append(args, "--goto", file)
+		}
+		return o.runner.Run(o.name, args...)
+	case editorJetBrains:
+		// JetBrains launchers may stay attached to the IDE process.
+		args = append(args, path)
+		if file != "" {
+			args = append(args, file)
+		}
+		return o.runner.Start(o.name, args...)
+	case editorZed:
+		args = append(args, path)
+		if file != "" {
+			args = append(args, file)
+		}
+		return o.runner.Run(o.name, args...)
+	default:
+		if file != "" {
+			args = append(args, file)
+		} else {
+			args = append(args, path)
+		}
+		return o.runner.RunAttached(path, o.name, args...)
+	}
+}
+
+func withoutWaitFlags(args []string) []string {
+	var kept []string
+	for _, arg := range args {
+		if arg == "--wait" || arg == "-w" {
+			continue
+		}
+		kept = append(kept, arg)
+	}
+	return kept
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// Combine returns an opener that opens a path with each opener in turn,
+// continuing past failures and returning them joined.
+func Combine(openers ...TabOpener) TabOpener {
+	return multiOpener(openers)
+}
+
+type multiOpener []TabOpener
+
+func (m multiOpener) Open(path string) error {
+	var errs []error
+	for _, opener := range m {
+		if err := opener.Open(path); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
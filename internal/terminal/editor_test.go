@@ -0,0 +1,91 @@
+package terminal
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func noEditorsOnPath(string) (string, error) {
+	return "", errors.New("not found")
+}
+
+func TestDetectEditorPrefersVisualThenEditorThenPath(t *testing.T) {
+	cases := []struct {
+		env      map[string]string
+		onPath   string
+		expected string
+	}{
+		{map[string]string{"VISUAL": "zed", "EDITOR": "vim"}, "", "zed"},
+		{map[string]string{"EDITOR": "nvim"}, "code", "nvim"},
+		{nil, "cursor", "cursor"},
+	}
+
+	for _, tc := range cases {
+		lookPath := func(name string) (string, error) {
+			if name == tc.onPath {
+				return "/usr/bin/" + name, nil
+			}
+			return "", errors.New("not found")
+		}
+		opener, err := detectEditor(EditorConfig{}, envFunc(tc.env), lookPath, &fakeRunner{})
+		if err != nil {
+			t.Fatalf("detectEditor(%v): %v", tc.env, err)
+		}
+		if got := opener.(editorOpener).name; got != tc.expected {
+			t.Fatalf("detectEditor(%v) = %s, want %s", tc.env, got, tc.expected)
+		}
+	}
+
+	if _, err := detectEditor(EditorConfig{}, envFunc(nil), noEditorsOnPath, &fakeRunner{}); err == nil {
+		t.Fatalf("expected error when no editor is available")
+	}
+}
+
+func TestEditorOpenersPassFolderAndFile(t *testing.T) {
+	cases := []struct {
+		editor   string
+		run      string
+		start    string
+		attached string
+	}{
+		{editor: "code --wait", run: "code /work/pr --goto /work/pr/main.go"},
+		{editor: "cursor", run: "cursor /work/pr --goto /work/pr/main.go"},
+		{editor: "goland", start: "goland /work/pr /work/pr/main.go"},
+		{editor: "zed", run: "zed /work/pr /work/pr/main.go"},
+		{editor: "nvim -O", attached: "/work/pr: nvim -O /work/pr/main.go"},
+	}
+
+	for _, tc := range cases {
+		runner := &fakeRunner{}
+		opener, err := detectEditor(EditorConfig{Editor: tc.editor, File: "main.go"}, envFunc(nil), noEditorsOnPath, runner)
+		if err != nil {
+			t.Fatalf("detect %s: %v", tc.editor, err)
+		}
+		if err := opener.Open("/work/pr"); err != nil {
+			t.Fatalf("open %s: %v", tc.editor, err)
+		}
+		got := strings.Join(append(append(runner.runs, runner.starts...), runner.attached...), "; ")
+		expected := tc.run + tc.start + tc.attached
+		if got != expected {
+			t.Fatalf("%s: expected %q, got %q", tc.editor, expected, got)
+		}
+	}
+}
+
+func TestCombineOpensAllAndJoinsErrors(t *testing.T) {
+	failing := &fakeRunner{runErr: errors.New("boom")}
+	ok := &fakeRunner{}
+	opener := Combine(
+		zellijOpener{runner: failing},
+		editorOpener{runner: ok, name: "zed", kind: editorZed},
+	)
+
+	err := opener.Open("/work/pr")
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("expected joined error, got %v", err)
+	}
+	if len(ok.runs) != 1 {
+		t.Fatalf("expected the editor to open despite the failure, got %v", ok.runs)
+	}
+}
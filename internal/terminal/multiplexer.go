@@ -0,0 +1,93 @@
+package terminal
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// detectMultiplexer returns a tmux or Zellij opener when term names one, or
+// when term is "auto" and prt runs inside one. Multiplexers take precedence
+// over the outer terminal because a new tab there would leave the session.
+func detectMultiplexer(cfg Config, term string, getenv func(string) string, runner CommandRunner) (TabOpener, bool) {
+	if term == "auto" {
+		switch {
+		case getenv("TMUX") != "":
+			term = "tmux"
+		case getenv("ZELLIJ") != "":
+			term = "zellij"
+		}
+	}
+
+	switch term {
+	case "tmux":
+		return tmuxOpener{runner: runner, name: cfg.Name, session: getenv("TMUX") == "", writer: os.Stderr}, true
+	case "tmux-session":
+		return tmuxOpener{runner: runner, name: cfg.Name, session: true, inside: getenv("TMUX") != "", writer: os.Stderr}, true
+	case "zellij":
+		return zellijOpener{runner: runner, name: cfg.Name}, true
+	default:
+		return nil, false
+	}
+}
+
+// tmuxOpener opens the worktree in a tmux window of the current session, or
+// in a detached session of its own. Windows and sessions are named after the
+// PR and reused when one with the same name already exists.
+type tmuxOpener struct {
+	runner CommandRunner
+	name   string
+	// session opens a named session instead of a window; inside switches
+	// the current client to it.
+	session bool
+	inside  bool
+	writer  io.Writer
+}
+
+func (o tmuxOpener) Open(path string) error {
+	name := tmuxName(o.name, path)
+	if !o.session {
+		if err := o.runner.Run("tmux", "select-window", "-t", "="+name); err == nil {
+			return nil
+		}
+		return o.runner.Run("tmux", "new-window", "-n", name, "-c", path)
+	}
+
+	if err := o.runner.Run("tmux", "has-session", "-t", "="+name); err != nil {
+		if err := o.runner.Run("tmux", "new-session", "-d", "-s", name, "-c", path); err != nil {
+			return err
+		}
+	}
+	if o.inside {
+		return o.runner.Run("tmux", "switch-client", "-t", "="+name)
+	}
+	if o.writer != nil {
+		fmt.Fprintf(o.writer, "Attach with: tmux attach -t %s\n", shellEscape(name))
+	}
+	return nil
+}
+
+// tmuxName returns a window or session name, falling back to the worktree
+// directory name. tmux treats '.' and ':' as target separators.
+func tmuxName(name string, path string) string {
+	if name == "" {
+		name = filepath.Base(path)
+	}
+	return strings.NewReplacer(".", "-", ":", "-").Replace(name)
+}
+
+// zellijOpener opens the worktree in a new tab of the current Zellij session.
+type zellijOpener struct {
+	runner CommandRunner
+	name   string
+}
+
+func (o zellijOpener) Open(path string) error {
+	args := []string{"action", "new-tab", "--cwd", path}
+	if o.name != "" {
+		args = append(args, "--name", o.name)
+	}
+	return o.runner.Run("zellij", args...)
+}
@@ -0,0 +1,147 @@
+package terminal
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type fakeRunner struct {
+	runs     []string
+	starts   []string
+	attached []string
+	runErr   error
+	// runErrs overrides runErr for specific commands.
+	runErrs map[string]error
+}
+
+func (f *fakeRunner) Run(name string, args ...string) error {
+	command := strings.Join(append([]string{name}, args...), " ")
+	f.runs = append(f.runs, command)
+	if err, ok := f.runErrs[command]; ok {
+		return err
+	}
+	return f.runErr
+}
+
+func (f *fakeRunner) RunAttached(dir string, name string, args ...string) error {
+	f.attached = append(f.attached, dir+": "+strings.Join(append([]string{name}, args...), " "))
+	return nil
+}
+
+func (f *fakeRunner) Start(name string, args ...string) error {
+	f.starts = append(f.starts, strings.Join(append([]string{name}, args...), " "))
+	return nil
+}
+
+func envFunc(values map[string]string) func(string) string {
+	return func(key string) string {
+		return values[key]
+	}
+}
+
+func TestDetectMultiplexerFromEnv(t *testing.T) {
+	cases := []struct {
+		env  map[string]string
+		want string
+	}{
+		{map[string]string{"TMUX": "/tmp/tmux-1000/default,1,0", "KITTY_WINDOW_ID": "1"}, "tmux"},
+		{map[string]string{"ZELLIJ": "0"}, "zellij"},
+		{map[string]string{"TERM_PROGRAM": "tmux"}, ""},
+	}
+
+	for _, tc := range cases {
+		opener, ok := detectMultiplexer(Config{}, "auto", envFunc(tc.env), &fakeRunner{})
+		var got string
+		switch opener.(type) {
+		case tmuxOpener:
+			got = "tmux"
+		case zellijOpener:
+			got = "zellij"
+		}
+		if got != tc.want || ok != (tc.want != "") {
+			t.Fatalf("detectMultiplexer(%v) = %T, %v; want %s", tc.env, opener, ok, tc.want)
+		}
+	}
+}
+
+func TestTmuxOpensNamedWindow(t *testing.T) {
+	runner := &fakeRunner{runErrs: map[string]error{
+		"tmux select-window -t =prt-go-pr-12": errors.New("can't find window"),
+	}}
+	env := envFunc(map[string]string{"TMUX": "/tmp/tmux-1000/default,1,0"})
+	opener, _ := detectMultiplexer(Config{Name: "prt.go-pr-12"}, "auto", env, runner)
+
+	if err := opener.Open("/work/prt-pr-12"); err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	expected := []string{
+		"tmux select-window -t =prt-go-pr-12",
+		"tmux new-window -n prt-go-pr-12 -c /work/prt-pr-12",
+	}
+	if strings.Join(runner.runs, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("expected %v, got %v", expected, runner.runs)
+	}
+}
+
+func TestTmuxReusesExistingWindow(t *testing.T) {
+	runner := &fakeRunner{}
+	env := envFunc(map[string]string{"TMUX": "/tmp/tmux-1000/default,1,0"})
+	opener, _ := detectMultiplexer(Config{Name: "prt-pr-12"}, "auto", env, runner)
+
+	if err := opener.Open("/work/prt-pr-12"); err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if len(runner.runs) != 1 || runner.runs[0] != "tmux select-window -t =prt-pr-12" {
+		t.Fatalf("expected only select-window, got %v", runner.runs)
+	}
+}
+
+func TestTmuxSessionInsideTmux(t *testing.T) {
+	runner := &fakeRunner{}
+	env := envFunc(map[string]string{"TMUX": "/tmp/tmux-1000/default,1,0"})
+	opener, _ := detectMultiplexer(Config{Name: "prt-pr-12"}, "tmux-session", env, runner)
+
+	if err := opener.Open("/work/prt-pr-12"); err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	expected := []string{
+		"tmux has-session -t =prt-pr-12",
+		"tmux switch-client -t =prt-pr-12",
+	}
+	if strings.Join(runner.runs, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("expected %v, got %v", expected, runner.runs)
+	}
+}
+
+func TestTmuxOutsideTmuxCreatesDetachedSession(t *testing.T) {
+	runner := &fakeRunner{runErrs: map[string]error{
+		"tmux has-session -t =prt-pr-12": errors.New("can't find session"),
+	}}
+	var out strings.Builder
+	opener := tmuxOpener{runner: runner, name: "prt-pr-12", session: true, writer: &out}
+
+	if err := opener.Open("/work/prt-pr-12"); err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if len(runner.runs) != 2 || runner.runs[1] != "tmux new-session -d -s prt-pr-12 -c /work/prt-pr-12" {
+		t.Fatalf("unexpected tmux calls: %v", runner.runs)
+	}
+	if !strings.Contains(out.String(), "tmux attach -t 'prt-pr-12'") {
+		t.Fatalf("expected attach hint, got %q", out.String())
+	}
+}
+
+func TestZellijOpensNamedTab(t *testing.T) {
+	runner := &fakeRunner{}
+	opener, ok := detectMultiplexer(Config{Terminal: "zellij", Name: "prt-pr-12"}, "zellij", envFunc(nil), runner)
+	if !ok {
+		t.Fatalf("expected zellij opener")
+	}
+	if err := opener.Open("/work/prt-pr-12"); err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if len(runner.runs) != 1 || runner.runs[0] != "zellij action new-tab --cwd /work/prt-pr-12 --name prt-pr-12" {
+		t.Fatalf("unexpected zellij call: %v", runner.runs)
+	}
+}
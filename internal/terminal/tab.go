@@ -10,6 +10,14 @@ import (
 // Config controls terminal opener selection.
 type Config struct {
 	Terminal string
+	// Name labels the tab, window, or session where the terminal supports
+	// it, e.g. "repo-pr-123".
+	Name string
+	// Command is a terminal_command template; see commandOpener.
+	Command []string
+	// Shell marks Command as a single shell command line.
+	Shell bool
+	PR    PRInfo
 }
 
 // TabOpener opens a terminal tab or prints a fallback path.
@@ -52,3 +60,10 @@ func normalizeTerminal(value string) string {
 	}
 	return value
 }
+
+func shellEscape(value string) string {
+	if value == "" {
+		return "''"
+	}
+	return "'" + strings.ReplaceAll(value, "'", "'\\''") + "'"
+}
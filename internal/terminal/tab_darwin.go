@@ -18,6 +18,12 @@ type opener struct {
 // Detect returns a macOS terminal opener based on configured preference.
 func Detect(cfg Config) (TabOpener, error) {
 	term := normalizeTerminal(cfg.Terminal)
+	if opener, ok, err := detectCommand(cfg, term, ExecRunner{}); ok {
+		return opener, err
+	}
+	if opener, ok := detectMultiplexer(cfg, term, os.Getenv, ExecRunner{}); ok {
+		return opener, nil
+	}
 	if term == "auto" {
 		term = detectFromEnv()
 	}
@@ -108,10 +114,3 @@ func escapeAppleScript(value string) string {
 	value = strings.ReplaceAll(value, "\"", "\\\"")
 	return value
 }
-
-func shellEscape(value string) string {
-	if value == "" {
-		return "''"
-	}
-	return "'" + strings.ReplaceAll(value, "'", "'\\''") + "'"
-}
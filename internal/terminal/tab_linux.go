@@ -0,0 +1,117 @@
+//go:build linux
+
+package terminal
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Detect returns a Linux terminal opener based on configured preference.
+func Detect(cfg Config) (TabOpener, error) {
+	return detectLinux(cfg, os.Getenv, ExecRunner{})
+}
+
+func detectLinux(cfg Config, getenv func(string) string, runner CommandRunner) (TabOpener, error) {
+	term := normalizeTerminal(cfg.Terminal)
+	if opener, ok, err := detectCommand(cfg, term, runner); ok {
+		return opener, err
+	}
+	if opener, ok := detectMultiplexer(cfg, term, getenv, runner); ok {
+		return opener, nil
+	}
+	if term == "auto" {
+		term = detectLinuxFromEnv(getenv)
+	}
+
+	switch term {
+	case "gnome-terminal", "gnome":
+		return gnomeTerminalOpener{runner: runner}, nil
+	case "konsole":
+		return konsoleOpener{runner: runner}, nil
+	case "kitty":
+		return kittyOpener{runner: runner}, nil
+	case "wezterm":
+		return weztermOpener{runner: runner}, nil
+	case "alacritty":
+		return alacrittyOpener{runner: runner}, nil
+	case "auto", "", "unknown":
+		return Printer{Writer: os.Stdout}, nil
+	default:
+		return nil, fmt.Errorf("unsupported terminal: %s", cfg.Terminal)
+	}
+}
+
+// detectLinuxFromEnv identifies the running terminal from variables it sets
+// in child shells. Terminal-specific variables are checked before generic
+// ones because they survive nesting (e.g. kitty launched from GNOME Terminal).
+func detectLinuxFromEnv(getenv func(string) string) string {
+	switch {
+	case getenv("KITTY_WINDOW_ID") != "":
+		return "kitty"
+	case getenv("WEZTERM_PANE") != "" || getenv("TERM_PROGRAM") == "WezTerm":
+		return "wezterm"
+	case getenv("ALACRITTY_WINDOW_ID") != "" || getenv("ALACRITTY_SOCKET") != "":
+		return "alacritty"
+	case getenv("KONSOLE_VERSION") != "":
+		return "konsole"
+	case getenv("GNOME_TERMINAL_SCREEN") != "" || getenv("GNOME_TERMINAL_SERVICE") != "":
+		return "gnome-terminal"
+	}
+	if strings.EqualFold(getenv("TERM"), "xterm-kitty") {
+		return "kitty"
+	}
+	return "unknown"
+}
+
+type gnomeTerminalOpener struct {
+	runner CommandRunner
+}
+
+func (o gnomeTerminalOpener) Open(path string) error {
+	return o.runner.Run("gnome-terminal", "--tab", "--working-directory="+path)
+}
+
+type konsoleOpener struct {
+	runner CommandRunner
+}
+
+// Open starts konsole detached because it stays in the foreground until the
+// new tab's window closes.
+func (o konsoleOpener) Open(path string) error {
+	return o.runner.Start("konsole", "--new-tab", "--workdir", path)
+}
+
+type kittyOpener struct {
+	runner CommandRunner
+}
+
+// Open uses kitty remote control, which requires allow_remote_control.
+func (o kittyOpener) Open(path string) error {
+	if err := o.runner.Run("kitty", "@", "launch", "--type=tab", "--cwd", path); err != nil {
+		return fmt.Errorf("kitty remote control failed (is allow_remote_control enabled?): %w", err)
+	}
+	return nil
+}
+
+type weztermOpener struct {
+	runner CommandRunner
+}
+
+func (o weztermOpener) Open(path string) error {
+	return o.runner.Run("wezterm", "cli", "spawn", "--cwd", path)
+}
+
+type alacrittyOpener struct {
+	runner CommandRunner
+}
+
+// Open asks the running Alacritty instance for a new window, since Alacritty
+// has no tabs, and falls back to starting a new instance.
+func (o alacrittyOpener) Open(path string) error {
+	if err := o.runner.Run("alacritty", "msg", "create-window", "--working-directory", path); err == nil {
+		return nil
+	}
+	return o.runner.Start("alacritty", "--working-directory", path)
+}
@@ -0,0 +1,89 @@
+//go:build linux
+
+package terminal
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDetectLinuxFromEnv(t *testing.T) {
+	cases := []struct {
+		env  map[string]string
+		want string
+	}{
+		{map[string]string{"KITTY_WINDOW_ID": "1", "GNOME_TERMINAL_SCREEN": "x"}, "kitty"},
+		{map[string]string{"WEZTERM_PANE": "0"}, "wezterm"},
+		{map[string]string{"TERM_PROGRAM": "WezTerm"}, "wezterm"},
+		{map[string]string{"ALACRITTY_WINDOW_ID": "42"}, "alacritty"},
+		{map[string]string{"KONSOLE_VERSION": "230804"}, "konsole"},
+		{map[string]string{"GNOME_TERMINAL_SERVICE": ":1.99"}, "gnome-terminal"},
+		{map[string]string{"TERM": "xterm-kitty"}, "kitty"},
+		{map[string]string{"TERM": "xterm-256color"}, "unknown"},
+	}
+
+	for _, tc := range cases {
+		if got := detectLinuxFromEnv(envFunc(tc.env)); got != tc.want {
+			t.Fatalf("detectLinuxFromEnv(%v) = %s, want %s", tc.env, got, tc.want)
+		}
+	}
+}
+
+func TestLinuxOpenersRunExpectedCommands(t *testing.T) {
+	cases := []struct {
+		terminal string
+		run      string
+		start    string
+	}{
+		{terminal: "gnome-terminal", run: "gnome-terminal --tab --working-directory=/work/pr 1"},
+		{terminal: "konsole", start: "konsole --new-tab --workdir /work/pr 1"},
+		{terminal: "kitty", run: "kitty @ launch --type=tab --cwd /work/pr 1"},
+		{terminal: "wezterm", run: "wezterm cli spawn --cwd /work/pr 1"},
+		{terminal: "alacritty", run: "alacritty msg create-window --working-directory /work/pr 1"},
+	}
+
+	for _, tc := range cases {
+		runner := &fakeRunner{}
+		opener, err := detectLinux(Config{Terminal: tc.terminal}, envFunc(nil), runner)
+		if err != nil {
+			t.Fatalf("detect %s: %v", tc.terminal, err)
+		}
+		if err := opener.Open("/work/pr 1"); err != nil {
+			t.Fatalf("open %s: %v", tc.terminal, err)
+		}
+		if tc.run != "" && (len(runner.runs) != 1 || runner.runs[0] != tc.run) {
+			t.Fatalf("%s: expected run %q, got %v", tc.terminal, tc.run, runner.runs)
+		}
+		if tc.start != "" && (len(runner.starts) != 1 || runner.starts[0] != tc.start) {
+			t.Fatalf("%s: expected start %q, got %v", tc.terminal, tc.start, runner.starts)
+		}
+	}
+}
+
+func TestAlacrittyFallsBackToNewInstance(t *testing.T) {
+	runner := &fakeRunner{runErr: errors.New("no socket")}
+	opener, err := detectLinux(Config{Terminal: "alacritty"}, envFunc(nil), runner)
+	if err != nil {
+		t.Fatalf("detect: %v", err)
+	}
+	if err := opener.Open("/work"); err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if len(runner.starts) != 1 || runner.starts[0] != "alacritty --working-directory /work" {
+		t.Fatalf("expected fallback start, got %v", runner.starts)
+	}
+}
+
+func TestDetectLinuxAutoAndUnsupported(t *testing.T) {
+	opener, err := detectLinux(Config{}, envFunc(nil), &fakeRunner{})
+	if err != nil {
+		t.Fatalf("detect auto: %v", err)
+	}
+	if _, ok := opener.(Printer); !ok {
+		t.Fatalf("expected Printer for unknown terminal, got %T", opener)
+	}
+
+	if _, err := detectLinux(Config{Terminal: "iterm2"}, envFunc(nil), &fakeRunner{}); err == nil {
+		t.Fatalf("expected error for unsupported terminal")
+	}
+}
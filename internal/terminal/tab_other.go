@@ -1,4 +1,4 @@
-//go:build !darwin
+//go:build !darwin && !linux
 
 package terminal
 
@@ -7,9 +7,16 @@ import (
 	"os"
 )
 
-// Detect returns a fallback opener on non-macOS systems.
+// Detect returns a tmux or Zellij opener when one is in use, and otherwise a
+// fallback opener on systems without tab support.
 func Detect(cfg Config) (TabOpener, error) {
 	term := normalizeTerminal(cfg.Terminal)
+	if opener, ok, err := detectCommand(cfg, term, ExecRunner{}); ok {
+		return opener, err
+	}
+	if opener, ok := detectMultiplexer(cfg, term, os.Getenv, ExecRunner{}); ok {
+		return opener, nil
+	}
 	if term == "auto" {
 		return Printer{Writer: os.Stdout}, nil
 	}
@@ -0,0 +1,82 @@
+package terminal
+
+import (
+	"errors"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// PRInfo describes the PR being opened, for command template placeholders.
+type PRInfo struct {
+	Number int
+	Title  string
+	// Repo is "owner/repo".
+	Repo   string
+	Branch string
+}
+
+// detectCommand returns a commandOpener when cfg.Command is set and term is
+// "auto" or "command". ok is false when another opener should be chosen.
+func detectCommand(cfg Config, term string, runner CommandRunner) (TabOpener, bool, error) {
+	switch {
+	case term == "command" && len(cfg.Command) == 0:
+		return nil, true, errors.New("terminal: command requires terminal_command to be set")
+	case len(cfg.Command) > 0 && (term == "auto" || term == "command"):
+		return commandOpener{runner: runner, template: cfg.Command, shell: cfg.Shell, pr: cfg.PR}, true, nil
+	default:
+		return nil, false, nil
+	}
+}
+
+// commandOpener runs a user-supplied command template. When shell is set,
+// the template's single element is a command line run with sh -c (cmd /C on
+// Windows), and placeholder values are quoted for that shell; otherwise each
+// element is one argument and no shell is involved.
+type commandOpener struct {
+	runner   CommandRunner
+	template []string
+	shell    bool
+	pr       PRInfo
+}
+
+func (o commandOpener) Open(path string) error {
+	values := map[string]string{
+		"path":   path,
+		"number": strconv.Itoa(o.pr.Number),
+		"title":  o.pr.Title,
+		"repo":   o.pr.Repo,
+		"branch": o.pr.Branch,
+	}
+	if o.shell {
+		if runtime.GOOS == "windows" {
+			return o.runner.Run("cmd", "/C", expandTemplate(strings.Join(o.template, " "), values, cmdEscape))
+		}
+		return o.runner.Run("sh", "-c", expandTemplate(strings.Join(o.template, " "), values, shellEscape))
+	}
+
+	args := make([]string, len(o.template))
+	for i, arg := range o.template {
+		args[i] = expandTemplate(arg, values, nil)
+	}
+	return o.runner.Run(args[0], args[1:]...)
+}
+
+// cmdEscape double-quotes value for cmd.exe. cmd has no escape for a double
+// quote inside quotes, so those are dropped.
+func cmdEscape(value string) string {
+	return `"` + strings.ReplaceAll(value, `"`, "") + `"`
+}
+
+// expandTemplate replaces {name} placeholders with values, passing each
+// value through escape when it is non-nil. Unknown placeholders are kept.
+func expandTemplate(template string, values map[string]string, escape func(string) string) string {
+	var pairs []string
+	for name, value := range values {
+		if escape != nil {
+			value = escape(value)
+		}
+		pairs = append(pairs, "{"+name+"}", value)
+	}
+	return strings.NewReplacer(pairs...).Replace(template)
+}
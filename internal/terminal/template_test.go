@@ -0,0 +1,66 @@
+package terminal
+
+import "testing"
+
+func TestCommandTemplateArgv(t *testing.T) {
+	runner := &fakeRunner{}
+	cfg := Config{
+		Command: []string{"wezterm", "cli", "spawn", "--cwd", "{path}", "--", "echo", "#{number} {title}"},
+		PR:      PRInfo{Number: 12, Title: "Fix 'quotes' & $HOME", Repo: "octo/repo", Branch: "fix"},
+	}
+	opener, ok, err := detectCommand(cfg, "auto", runner)
+	if !ok || err != nil {
+		t.Fatalf("expected command opener, got ok=%v err=%v", ok, err)
+	}
+	if err := opener.Open("/work/pr 12"); err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	expected := "wezterm cli spawn --cwd /work/pr 12 -- echo #12 Fix 'quotes' & $HOME"
+	if len(runner.runs) != 1 || runner.runs[0] != expected {
+		t.Fatalf("expected %q, got %v", expected, runner.runs)
+	}
+}
+
+func TestCommandTemplateShellEscapesValues(t *testing.T) {
+	runner := &fakeRunner{}
+	opener := commandOpener{
+		runner:   runner,
+		template: []string{"mux open --dir {path} --name {repo}#{number} --branch {branch} {unknown}"},
+		shell:    true,
+		pr:       PRInfo{Number: 12, Repo: "octo/repo", Branch: "it's"},
+	}
+	if err := opener.Open("/work/pr 12"); err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	expected := `sh -c mux open --dir '/work/pr 12' --name 'octo/repo'#'12' --branch 'it'\''s' {unknown}`
+	if len(runner.runs) != 1 || runner.runs[0] != expected {
+		t.Fatalf("expected %q, got %v", expected, runner.runs)
+	}
+}
+
+func TestCommandTemplateSingleArgumentWithoutShell(t *testing.T) {
+	runner := &fakeRunner{}
+	opener, ok, err := detectCommand(Config{Command: []string{"/opt/My Term/bin/term"}}, "command", runner)
+	if !ok || err != nil {
+		t.Fatalf("expected command opener, got ok=%v err=%v", ok, err)
+	}
+	if err := opener.Open("/work"); err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if len(runner.runs) != 1 || runner.runs[0] != "/opt/My Term/bin/term" {
+		t.Fatalf("expected the program to run without a shell, got %v", runner.runs)
+	}
+}
+
+func TestDetectCommandSelection(t *testing.T) {
+	withCommand := Config{Command: []string{"term {path}"}}
+	if _, ok, _ := detectCommand(withCommand, "kitty", &fakeRunner{}); ok {
+		t.Fatalf("expected an explicit terminal to take precedence over terminal_command")
+	}
+	if _, ok, _ := detectCommand(Config{}, "auto", &fakeRunner{}); ok {
+		t.Fatalf("expected no command opener without a template")
+	}
+	if _, ok, err := detectCommand(Config{}, "command", &fakeRunner{}); !ok || err == nil {
+		t.Fatalf("expected error for terminal: command without a template")
+	}
+}
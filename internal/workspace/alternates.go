@@ -0,0 +1,92 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BradyPlanden/prt/internal/config"
+	"github.com/BradyPlanden/prt/internal/github"
+)
+
+// referenceRepoDir returns the persistent clone of pr's base repository for
+// a new temp bare clone to borrow objects from, or "" when there is none.
+// Shallow and partial clones are skipped: git cannot borrow from the first,
+// and the second lacks objects the temp clone would assume it has.
+func (r *Resolver) referenceRepoDir(ctx context.Context, cfg config.Config, pr github.PRMetadata) string {
+	dir, _ := r.mainCheckoutDir(ctx, cfg, pr, Result{}, ModeTemp)
+	if dir == "" {
+		return ""
+	}
+	if shallow, err := r.git.IsShallow(ctx, dir); err != nil || shallow {
+		return ""
+	}
+	if partial, err := r.git.IsPartialClone(ctx, dir); err != nil || partial {
+		return ""
+	}
+	return dir
+}
+
+// repairAlternates checks the alternate object stores of the bare repository
+// at bareDir. When one has been moved or deleted, it is dropped and the
+// missing objects are fetched again from the remotes.
+func (r *Resolver) repairAlternates(ctx context.Context, bareDir string) []string {
+	missing, valid, err := readAlternates(bareDir)
+	if err != nil {
+		return []string{fmt.Sprintf("could not check alternate object stores: %v", err)}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	if err := writeAlternates(bareDir, valid); err != nil {
+		return []string{fmt.Sprintf("could not remove missing alternate object store %s: %v", strings.Join(missing, ", "), err)}
+	}
+	if err := r.git.RefetchAll(ctx, bareDir); err != nil {
+		return []string{fmt.Sprintf("reference repository %s is gone and objects could not be fetched again: %v", strings.Join(missing, ", "), err)}
+	}
+	return []string{fmt.Sprintf("reference repository %s is gone; fetched its objects again", strings.Join(missing, ", "))}
+}
+
+func alternatesPath(bareDir string) string {
+	return filepath.Join(bareDir, "objects", "info", "alternates")
+}
+
+// readAlternates splits bareDir's alternate object stores into those that
+// no longer exist and the rest.
+func readAlternates(bareDir string) ([]string, []string, error) {
+	data, err := os.ReadFile(alternatesPath(bareDir))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+	var missing, valid []string
+	for line := range strings.SplitSeq(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// Relative entries are relative to the objects directory.
+		path := line
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(bareDir, "objects", path)
+		}
+		if isDir(path) {
+			valid = append(valid, line)
+		} else {
+			missing = append(missing, line)
+		}
+	}
+	return missing, valid, nil
+}
+
+func writeAlternates(bareDir string, entries []string) error {
+	if len(entries) == 0 {
+		return os.Remove(alternatesPath(bareDir))
+	}
+	return os.WriteFile(alternatesPath(bareDir), []byte(strings.Join(entries, "\n")+"\n"), 0o644)
+}
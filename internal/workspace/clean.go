@@ -0,0 +1,372 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BradyPlanden/prt/internal/git"
+)
+
+// PRStateFunc looks up the GitHub state ("OPEN", "MERGED", "CLOSED") of the
+// PR a worktree belongs to.
+type PRStateFunc func(ctx context.Context, info WorktreeInfo) (string, error)
+
+// PersistentCleanOptions configures CleanPersistent.
+type PersistentCleanOptions struct {
+	// TTL removes worktrees unused for at least TTL; zero disables idle cleanup.
+	TTL    time.Duration
+	DryRun bool
+	// PRState, when set, is used to remove worktrees whose PR is in one of
+	// States ("MERGED" and "CLOSED" when empty).
+	PRState PRStateFunc
+	States  []string
+}
+
+// CleanPersistent removes prt-created worktrees under projectsDir whose PR is
+// merged or closed, or that have been idle past opts.TTL. Removed worktrees'
+// pr/<N>/... branches are deleted as well, and so are their fork remotes once
+// no remaining worktree or branch uses them. Worktrees with uncommitted
+// changes or with commits not on their upstream or PR head are skipped.
+func (r *Resolver) CleanPersistent(ctx context.Context, projectsDir string, opts PersistentCleanOptions) ([]CleanResult, error) {
+	repoDirs, err := persistentRepoDirs(projectsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []CleanResult
+	for _, repoDir := range repoDirs {
+		isRepo, err := r.git.IsGitRepo(ctx, repoDir)
+		if err != nil {
+			return nil, err
+		}
+		if !isRepo {
+			continue
+		}
+		if err := r.cleanPersistentRepo(ctx, repoDir, opts, &results); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+func (r *Resolver) cleanPersistentRepo(ctx context.Context, repoDir string, opts PersistentCleanOptions, results *[]CleanResult) error {
+	worktreesDir := persistentWorktreesDir(repoDir)
+	infos, err := r.listRepoWorktrees(ctx, repoDir, worktreesDir, false, func(path string) (string, bool) {
+		if !sameDir(filepath.Dir(path), worktreesDir) {
+			return "", false
+		}
+		return filepath.Base(path), true
+	})
+	if err != nil {
+		return err
+	}
+
+	// Fork remotes are read from the records before removal deletes them.
+	forkRemotes := make(map[string]string, len(infos))
+	for _, info := range infos {
+		forkRemotes[info.Path] = recordForkRemote(worktreesDir, info.Path)
+	}
+
+	now := time.Now()
+	var deletedBranches []prBranch
+	removed := make(map[string]bool)
+	prunedMissing := false
+	for _, info := range infos {
+		if info.Missing {
+			action := CleanActionPruned
+			if opts.DryRun {
+				action = CleanActionWouldPrune
+			}
+			*results = append(*results, CleanResult{
+				Path:   info.Path,
+				Action: action,
+				Reason: "worktree path is missing",
+			})
+			removed[info.Path] = true
+			prunedMissing = true
+			if !opts.DryRun {
+				if err := removeWorktreeMarker(worktreesDir, info.Path); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		reason, byState, err := persistentRemovalReason(ctx, info, now, opts)
+		if err != nil {
+			*results = append(*results, CleanResult{
+				Path:   info.Path,
+				Action: CleanActionSkipped,
+				Reason: err.Error(),
+			})
+			continue
+		}
+		if reason == "" {
+			continue
+		}
+		if info.Dirty {
+			*results = append(*results, CleanResult{
+				Path:   info.Path,
+				Action: CleanActionSkipped,
+				Reason: "worktree has uncommitted changes",
+			})
+			continue
+		}
+		if skip := r.unpushedCommitsReason(ctx, worktreesDir, info); skip != "" {
+			*results = append(*results, CleanResult{
+				Path:   info.Path,
+				Action: CleanActionSkipped,
+				Reason: skip,
+			})
+			continue
+		}
+
+		action := CleanActionRemoved
+		if opts.DryRun {
+			action = CleanActionWouldRemove
+		}
+		*results = append(*results, CleanResult{Path: info.Path, Action: action, Reason: reason})
+		removed[info.Path] = true
+		if !opts.DryRun {
+			if err := r.git.WorktreeRemove(ctx, repoDir, info.Path, true); err != nil {
+				return err
+			}
+			if err := removeWorktreeMarker(worktreesDir, info.Path); err != nil {
+				return err
+			}
+		}
+		if isNamespacedPRBranch(info.Branch) {
+			deletedBranches = append(deletedBranches, prBranch{name: info.Branch, force: byState})
+		}
+	}
+
+	if prunedMissing && !opts.DryRun {
+		if err := r.git.WorktreePrune(ctx, repoDir); err != nil {
+			return err
+		}
+	}
+	action := CleanActionRemoved
+	if opts.DryRun {
+		action = CleanActionWouldRemove
+	}
+	var deleted []string
+	for _, branch := range deletedBranches {
+		if !opts.DryRun {
+			// Branches of idle worktrees are only deleted once merged
+			// (git branch -d); those of merged or closed PRs are forced.
+			if err := r.git.DeleteBranch(ctx, repoDir, branch.name, branch.force); err != nil {
+				*results = append(*results, CleanResult{
+					Path:   repoDir,
+					Action: CleanActionSkipped,
+					Branch: branch.name,
+					Reason: err.Error(),
+				})
+				continue
+			}
+		}
+		*results = append(*results, CleanResult{Path: repoDir, Action: action, Branch: branch.name})
+		deleted = append(deleted, branch.name)
+	}
+
+	candidates := make(map[string]bool)
+	inUse := make(map[string]bool)
+	for path, remote := range forkRemotes {
+		if remote == "" {
+			continue
+		}
+		if removed[path] {
+			candidates[remote] = true
+		} else {
+			inUse[remote] = true
+		}
+	}
+	unused, err := r.unusedForkRemotes(ctx, repoDir, deleted, candidates, inUse)
+	if err != nil {
+		return err
+	}
+	for _, remote := range unused {
+		*results = append(*results, CleanResult{Path: repoDir, Action: action, Remote: remote})
+		if !opts.DryRun {
+			if err := r.git.RemoveRemote(ctx, repoDir, remote); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// prBranch is a pr/<N>/... branch to delete; force is set when its PR is
+// merged or closed.
+type prBranch struct {
+	name  string
+	force bool
+}
+
+// unpushedCommitsReason returns why info must be kept because it may hold
+// commits that exist nowhere else, or "" when every commit in it is on its
+// upstream or is the PR head prt checked out.
+func (r *Resolver) unpushedCommitsReason(ctx context.Context, worktreesDir string, info WorktreeInfo) string {
+	var known []string
+	upstream, err := r.git.Upstream(ctx, info.Path)
+	if err != nil {
+		return fmt.Sprintf("could not check for unpushed commits: %v", err)
+	}
+	if upstream != "" {
+		known = append(known, upstream)
+	}
+	if record, ok, err := readWorktreeRecord(worktreesDir, info.Path); err == nil && ok && record.HeadSHA != "" {
+		known = append(known, record.HeadSHA)
+	}
+	if len(known) == 0 {
+		return "unpushed commits could not be ruled out"
+	}
+	local, err := r.git.CountCommits(ctx, info.Path, "HEAD", known...)
+	if err != nil {
+		return fmt.Sprintf("could not check for unpushed commits: %v", err)
+	}
+	if local > 0 {
+		return "worktree has unpushed commits"
+	}
+	return ""
+}
+
+// persistentRemovalReason returns why info should be removed, or "" to keep
+// it, and whether the reason is the PR's state rather than idleness. A PR
+// state lookup failure is returned as an error only when the worktree is not
+// otherwise removable.
+func persistentRemovalReason(ctx context.Context, info WorktreeInfo, now time.Time, opts PersistentCleanOptions) (string, bool, error) {
+	states := opts.States
+	if len(states) == 0 {
+		states = []string{"MERGED", "CLOSED"}
+	}
+
+	var stateErr error
+	if opts.PRState != nil && info.PRURL() != "" {
+		state, err := opts.PRState(ctx, info)
+		if err != nil {
+			stateErr = fmt.Errorf("could not look up PR state: %v", err)
+		} else if reason := stateRemovalReason(state, states); reason != "" {
+			return reason, true, nil
+		}
+	}
+
+	if opts.TTL > 0 {
+		lastUsed := info.LastUsed
+		if lastUsed.IsZero() {
+			stat, err := os.Stat(info.Path)
+			if err != nil {
+				return "", false, fmt.Errorf("stat worktree for idle check: %w", err)
+			}
+			lastUsed = stat.ModTime()
+		}
+		if idle := now.Sub(lastUsed); idle >= opts.TTL {
+			return fmt.Sprintf("idle for %s", idle.Truncate(time.Hour)), false, nil
+		}
+	}
+
+	return "", false, stateErr
+}
+
+// stateRemovalReason returns a cleanup reason such as "PR merged" when state
+// is one of states, or "".
+func stateRemovalReason(state string, states []string) string {
+	state = strings.ToUpper(strings.TrimSpace(state))
+	if state == "" || state == "OPEN" {
+		return ""
+	}
+	for _, candidate := range states {
+		if strings.EqualFold(candidate, state) {
+			return "PR " + strings.ToLower(state)
+		}
+	}
+	return ""
+}
+
+// tempWorktreeInfo describes a temp worktree of bareDir for PR state lookups.
+func (r *Resolver) tempWorktreeInfo(ctx context.Context, bareDir string, wt git.Worktree) (WorktreeInfo, error) {
+	tempDir := filepath.Dir(bareDir)
+	info := WorktreeInfo{
+		Path:    wt.Path,
+		RepoDir: bareDir,
+		Branch:  strings.TrimPrefix(wt.Branch, "refs/heads/"),
+		Temp:    true,
+	}
+	prefix := strings.TrimSuffix(filepath.Base(bareDir), ".git") + "-"
+	if number, ok := prNumberFromWorktreeName(strings.TrimPrefix(filepath.Base(wt.Path), prefix)); ok {
+		info.Number = number
+	}
+	if origin, err := r.git.OriginURL(ctx, bareDir); err == nil {
+		info.Repo, _ = RepoFromRemote(origin)
+	}
+
+	url, err := worktreePRURL(tempDir, wt.Path)
+	if err != nil {
+		return WorktreeInfo{}, err
+	}
+	info.URL = url
+	return info, nil
+}
+
+// recordForkRemote returns the prt/<owner>/<repo> remote recorded for the
+// worktree at path, or "" when it has no record or is not from a fork.
+func recordForkRemote(metaRoot string, path string) string {
+	record, ok, err := readWorktreeRecord(metaRoot, path)
+	if err != nil || !ok || record.HeadRepo == "" || strings.EqualFold(record.HeadRepo, record.BaseRepo) {
+		return ""
+	}
+	return "prt/" + record.HeadRepo
+}
+
+// unusedForkRemotes returns the candidates, the fork remotes of removed
+// worktrees, that still exist and that neither a remaining worktree (inUse)
+// nor a local branch left once deletedBranches are gone refers to.
+func (r *Resolver) unusedForkRemotes(ctx context.Context, repoDir string, deletedBranches []string, candidates map[string]bool, inUse map[string]bool) ([]string, error) {
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+	remotes, err := r.git.Remotes(ctx, repoDir)
+	if err != nil {
+		return nil, err
+	}
+	upstreams, err := r.git.BranchUpstreamRemotes(ctx, repoDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, branch := range deletedBranches {
+		delete(upstreams, branch)
+	}
+
+	used := make(map[string]struct{}, len(upstreams))
+	for _, remote := range upstreams {
+		used[remote] = struct{}{}
+	}
+
+	var unused []string
+	for _, remote := range remotes {
+		if !candidates[remote] || inUse[remote] {
+			continue
+		}
+		if _, ok := used[remote]; !ok {
+			unused = append(unused, remote)
+		}
+	}
+	sort.Strings(unused)
+	return unused, nil
+}
+
+// isNamespacedPRBranch reports whether branch is a prt-created pr/<N>/...
+// branch. Same-repo PRs check out the head branch name itself, which may be
+// a user's own branch, so those are never deleted.
+func isNamespacedPRBranch(branch string) bool {
+	rest, ok := strings.CutPrefix(branch, "pr/")
+	if !ok {
+		return false
+	}
+	number, _, ok := strings.Cut(rest, "/")
+	return ok && number != "" && strings.Trim(number, "0123456789") == ""
+}
@@ -0,0 +1,177 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BradyPlanden/prt/internal/config"
+	"github.com/BradyPlanden/prt/internal/github"
+)
+
+// copyFiles brings untracked files from the main checkout into a new
+// worktree: projectCopy from .prt.yaml, then the copy_files rules matching
+// the base repository in sorted pattern order. Existing files in the
+// worktree are never replaced.
+func (r *Resolver) copyFiles(ctx context.Context, cfg config.Config, pr github.PRMetadata, result Result, mode string, projectCopy []string) []string {
+	rules := []config.CopyRule{{Copy: projectCopy}}
+	patterns := make([]string, 0, len(cfg.CopyFiles))
+	for pattern := range cfg.CopyFiles {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+	for _, pattern := range patterns {
+		if config.MatchesRepo(pattern, repoFullName(pr.BaseRepo)) {
+			rules = append(rules, cfg.CopyFiles[pattern])
+		}
+	}
+	if !hasCopyPatterns(rules) {
+		return nil
+	}
+
+	mainDir, warning := r.mainCheckoutDir(ctx, cfg, pr, result, mode)
+	if mainDir == "" {
+		return []string{warning}
+	}
+
+	var warnings []string
+	seen := make(map[string]bool)
+	for _, rule := range rules {
+		warnings = append(warnings, copyIntoWorktree(mainDir, result.Path, rule.Copy, false, seen)...)
+		warnings = append(warnings, copyIntoWorktree(mainDir, result.Path, rule.Symlink, true, seen)...)
+	}
+	return warnings
+}
+
+func hasCopyPatterns(rules []config.CopyRule) bool {
+	for _, rule := range rules {
+		if len(rule.Copy) > 0 || len(rule.Symlink) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// mainCheckoutDir returns the repository's primary clone. Temp worktrees
+// hang off a bare clone, so the persistent clone is used if there is one.
+// When there is none it returns "" and a warning.
+func (r *Resolver) mainCheckoutDir(ctx context.Context, cfg config.Config, pr github.PRMetadata, result Result, mode string) (string, string) {
+	if mode != ModeTemp {
+		return result.RepoDir, ""
+	}
+	dir, err := resolveRepoDir(ctx, r.git, cfg.ProjectsDir, pr.BaseRepo, nil)
+	if err != nil {
+		return "", fmt.Sprintf("could not find main checkout to copy files from: %v", err)
+	}
+	if isRepo, err := r.git.IsGitRepo(ctx, dir); err != nil || !isRepo {
+		return "", fmt.Sprintf("not copying files: no main checkout of %s under %s", repoFullName(pr.BaseRepo), cfg.ProjectsDir)
+	}
+	return dir, ""
+}
+
+// copyIntoWorktree copies or symlinks the files and directories matching
+// patterns (relative to mainDir) to the same place in worktreePath. Matches
+// outside mainDir are refused. seen
+// records destinations already handled so overlapping rules do not warn.
+func copyIntoWorktree(mainDir string, worktreePath string, patterns []string, symlink bool, seen map[string]bool) []string {
+	var warnings []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(filepath.Join(mainDir, filepath.FromSlash(pattern)))
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("invalid copy pattern %s: %v", pattern, err))
+			continue
+		}
+		if len(matches) == 0 {
+			warnings = append(warnings, fmt.Sprintf("not copying %s: no such file in %s", pattern, mainDir))
+			continue
+		}
+		for _, src := range matches {
+			rel, err := filepath.Rel(mainDir, src)
+			if err != nil {
+				continue
+			}
+			if filepath.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				warnings = append(warnings, fmt.Sprintf("not copying %s: it is outside the main checkout", filepath.ToSlash(rel)))
+				continue
+			}
+			if seen[rel] {
+				continue
+			}
+			seen[rel] = true
+			dst := filepath.Join(worktreePath, rel)
+			if _, err := os.Lstat(dst); err == nil {
+				warnings = append(warnings, fmt.Sprintf("not copying %s: it already exists in the worktree", filepath.ToSlash(rel)))
+				continue
+			}
+			if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+				warnings = append(warnings, fmt.Sprintf("could not copy %s: %v", filepath.ToSlash(rel), err))
+				continue
+			}
+			if symlink {
+				err = os.Symlink(src, dst)
+			} else {
+				err = copyPath(src, dst)
+			}
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("could not copy %s: %v", filepath.ToSlash(rel), err))
+			}
+		}
+	}
+	return warnings
+}
+
+// copyPath copies a file, symlink, or directory tree from src to dst.
+func copyPath(src string, dst string) error {
+	return filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case entry.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm()|0o700)
+		case info.Mode()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case info.Mode().IsRegular():
+			return copyFile(path, target, info.Mode().Perm())
+		default:
+			return nil
+		}
+	})
+}
+
+func copyFile(src string, dst string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
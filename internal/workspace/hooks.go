@@ -0,0 +1,142 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BradyPlanden/prt/internal/config"
+	"github.com/BradyPlanden/prt/internal/github"
+)
+
+const (
+	hookPostCreate = "post_create"
+	hookPostReuse  = "post_reuse"
+
+	// hookOutputLines is how much of a failing hook's output is kept in its
+	// warning.
+	hookOutputLines = 20
+)
+
+// HookRunner runs a hook command line in dir with env added to the
+// environment, returning its combined output.
+type HookRunner interface {
+	RunHook(ctx context.Context, dir string, command string, env []string) (string, error)
+}
+
+// ShellHookRunner runs hooks with sh -c, or cmd /C on Windows.
+type ShellHookRunner struct{}
+
+// RunHook runs command and captures its output.
+func (ShellHookRunner) RunHook(ctx context.Context, dir string, command string, env []string) (string, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	// Background processes started by a hook can hold the output pipe open
+	// after the shell is killed.
+	cmd.WaitDelay = time.Second
+	output, err := cmd.CombinedOutput()
+	return string(output), err
+}
+
+// runHooks runs the post_create or post_reuse hooks matching pr's base
+// repository in result.Path. Failures are returned as warnings.
+func (r *Resolver) runHooks(ctx context.Context, hooks config.Hooks, pr github.PRMetadata, result Result, mode string) []string {
+	event, entries := hookPostCreate, hooks.PostCreate
+	if result.Reused {
+		event, entries = hookPostReuse, hooks.PostReuse
+	}
+	commands := matchingHookCommands(entries, repoFullName(pr.BaseRepo))
+	if len(commands) == 0 {
+		return nil
+	}
+
+	env := hookEnv(pr, result, mode, event)
+	var warnings []string
+	for _, command := range commands {
+		if err := r.runHook(ctx, hooks.Timeout, result.Path, command, env); err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s hook %q failed: %v", event, command, err))
+		}
+	}
+	return warnings
+}
+
+func (r *Resolver) runHook(ctx context.Context, timeout time.Duration, dir string, command string, env []string) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	output, err := r.hooks.RunHook(ctx, dir, command, env)
+	if err == nil {
+		return nil
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("timed out after %s", timeout)
+	}
+	if tail := outputTail(output, hookOutputLines); tail != "" {
+		return fmt.Errorf("%w\n%s", err, tail)
+	}
+	return err
+}
+
+// matchingHookCommands returns the commands of every pattern matching repo
+// (see config.MatchesRepo), in sorted pattern order.
+func matchingHookCommands(entries map[string][]string, repo string) []string {
+	patterns := make([]string, 0, len(entries))
+	for pattern := range entries {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	var commands []string
+	for _, pattern := range patterns {
+		if !config.MatchesRepo(pattern, repo) {
+			continue
+		}
+		for _, command := range entries[pattern] {
+			if command = strings.TrimSpace(command); command != "" {
+				commands = append(commands, command)
+			}
+		}
+	}
+	return commands
+}
+
+func hookEnv(pr github.PRMetadata, result Result, mode string, event string) []string {
+	return []string{
+		"PRT_HOOK=" + event,
+		"PRT_MODE=" + mode,
+		"PRT_WORKTREE=" + result.Path,
+		"PRT_REPO_DIR=" + result.RepoDir,
+		"PRT_PR_NUMBER=" + strconv.Itoa(pr.Number),
+		"PRT_PR_URL=" + prURL(pr),
+		"PRT_PR_TITLE=" + pr.Title,
+		"PRT_BASE_REPO=" + repoFullName(pr.BaseRepo),
+		"PRT_BASE_REF=" + pr.BaseRef,
+		"PRT_HEAD_REPO=" + repoFullName(pr.HeadRepo),
+		"PRT_HEAD_REF=" + pr.HeadRef,
+	}
+}
+
+// outputTail returns the last n lines of output.
+func outputTail(output string, n int) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
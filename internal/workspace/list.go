@@ -0,0 +1,252 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BradyPlanden/prt/internal/config"
+	"github.com/BradyPlanden/prt/internal/github"
+)
+
+// WorktreeInfo describes one prt-managed worktree found on disk.
+type WorktreeInfo struct {
+	Path    string
+	RepoDir string
+	// Repo is parsed from the origin remote and may be empty when the
+	// remote is missing or unrecognized.
+	Repo   github.Repository
+	Number int
+	Branch string
+	Temp   bool
+	// Missing reports a worktree registered with git whose path is gone.
+	Missing bool
+	Dirty   bool
+	// LastUsed is zero when no usage marker has been recorded.
+	LastUsed time.Time
+	// URL and Title come from the worktree's metadata record and are empty
+	// for worktrees created by older prt versions.
+	URL   string
+	Title string
+}
+
+// PRURL returns the PR web URL for the worktree, or "" when unknown.
+func (w WorktreeInfo) PRURL() string {
+	if w.URL != "" {
+		return w.URL
+	}
+	if w.Number == 0 || w.Repo.Owner == "" || w.Repo.Name == "" {
+		return ""
+	}
+	ref := github.PRRef{Host: w.Repo.HostName(), Owner: w.Repo.Owner, Repo: w.Repo.Name, Number: w.Number}
+	return ref.URL()
+}
+
+// List returns persistent worktrees under cfg.ProjectsDir followed by temp
+// worktrees under cfg.TempDir.
+func (r *Resolver) List(ctx context.Context, cfg config.Config) ([]WorktreeInfo, error) {
+	persistent, err := r.listPersistent(ctx, cfg.ProjectsDir)
+	if err != nil {
+		return nil, err
+	}
+	temp, err := r.listTemp(ctx, cfg.TempDir)
+	if err != nil {
+		return nil, err
+	}
+	return append(persistent, temp...), nil
+}
+
+func (r *Resolver) listPersistent(ctx context.Context, projectsDir string) ([]WorktreeInfo, error) {
+	repoDirs, err := persistentRepoDirs(projectsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var infos []WorktreeInfo
+	for _, repoDir := range repoDirs {
+		isRepo, err := r.git.IsGitRepo(ctx, repoDir)
+		if err != nil {
+			return nil, err
+		}
+		if !isRepo {
+			continue
+		}
+		worktreesDir := persistentWorktreesDir(repoDir)
+		found, err := r.listRepoWorktrees(ctx, repoDir, worktreesDir, false, func(path string) (string, bool) {
+			if !sameDir(filepath.Dir(path), worktreesDir) {
+				return "", false
+			}
+			return filepath.Base(path), true
+		})
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, found...)
+	}
+	return infos, nil
+}
+
+func (r *Resolver) listTemp(ctx context.Context, tempDir string) ([]WorktreeInfo, error) {
+	bareDirs, err := tempBareRepoDirs(tempDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var infos []WorktreeInfo
+	for _, bareDir := range bareDirs {
+		prefix := strings.TrimSuffix(filepath.Base(bareDir), ".git") + "-"
+		found, err := r.listRepoWorktrees(ctx, bareDir, tempDir, true, func(path string) (string, bool) {
+			if path == bareDir {
+				return "", false
+			}
+			return strings.TrimPrefix(filepath.Base(path), prefix), true
+		})
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, found...)
+	}
+	return infos, nil
+}
+
+// listRepoWorktrees inspects the worktrees of repoDir accepted by match,
+// which returns the pr-<N>-<branch> directory name for prt-created paths.
+func (r *Resolver) listRepoWorktrees(ctx context.Context, repoDir string, metaRoot string, temp bool, match func(path string) (string, bool)) ([]WorktreeInfo, error) {
+	worktrees, err := r.git.WorktreeList(ctx, repoDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var repo github.Repository
+	if origin, err := r.git.OriginURL(ctx, repoDir); err == nil {
+		repo, _ = RepoFromRemote(origin)
+	}
+
+	var infos []WorktreeInfo
+	for _, wt := range worktrees {
+		name, ok := match(wt.Path)
+		if !ok {
+			continue
+		}
+		number, ok := prNumberFromWorktreeName(name)
+		if !ok {
+			continue
+		}
+
+		info := WorktreeInfo{
+			Path:    wt.Path,
+			RepoDir: repoDir,
+			Repo:    repo,
+			Number:  number,
+			Branch:  strings.TrimPrefix(wt.Branch, "refs/heads/"),
+			Temp:    temp,
+		}
+		if _, err := os.Stat(wt.Path); err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				return nil, fmt.Errorf("stat worktree: %w", err)
+			}
+			info.Missing = true
+		} else {
+			dirty, err := r.git.IsWorktreeDirty(ctx, wt.Path)
+			if err != nil {
+				return nil, err
+			}
+			info.Dirty = dirty
+		}
+
+		record, ok, err := readWorktreeRecord(metaRoot, wt.Path)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			info.LastUsed = record.LastUsedAt
+			info.URL = record.URL
+			info.Title = record.Title
+			if record.Number > 0 {
+				info.Number = record.Number
+			}
+		}
+		infos = append(infos, info)
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Number != infos[j].Number {
+			return infos[i].Number < infos[j].Number
+		}
+		return infos[i].Path < infos[j].Path
+	})
+	return infos, nil
+}
+
+// persistentRepoDirs returns repositories in projectsDir that have a sibling
+// <repo>-worktrees directory.
+func persistentRepoDirs(projectsDir string) ([]string, error) {
+	entries, err := os.ReadDir(projectsDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read projects dir: %w", err)
+	}
+
+	var repoDirs []string
+	for _, entry := range entries {
+		repoName, ok := strings.CutSuffix(entry.Name(), "-worktrees")
+		if !entry.IsDir() || !ok || repoName == "" {
+			continue
+		}
+		repoDir := filepath.Join(projectsDir, repoName)
+		if pathExists(repoDir) {
+			repoDirs = append(repoDirs, repoDir)
+		}
+	}
+	return repoDirs, nil
+}
+
+func tempBareRepoDirs(tempDir string) ([]string, error) {
+	entries, err := os.ReadDir(tempDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read temp dir: %w", err)
+	}
+
+	var bareDirs []string
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasSuffix(entry.Name(), ".git") {
+			continue
+		}
+		bareDirs = append(bareDirs, filepath.Join(tempDir, entry.Name()))
+	}
+	return bareDirs, nil
+}
+
+// prNumberFromWorktreeName extracts N from a pr-<N>-<branch> directory name.
+func prNumberFromWorktreeName(name string) (int, bool) {
+	rest, ok := strings.CutPrefix(name, "pr-")
+	if !ok {
+		return 0, false
+	}
+	digits, _, _ := strings.Cut(rest, "-")
+	number, err := strconv.Atoi(digits)
+	if err != nil || number <= 0 {
+		return 0, false
+	}
+	return number, true
+}
+
+func sameDir(a string, b string) bool {
+	if a == b {
+		return true
+	}
+	resolvedA, errA := filepath.EvalSymlinks(a)
+	resolvedB, errB := filepath.EvalSymlinks(b)
+	return errA == nil && errB == nil && resolvedA == resolvedB
+}
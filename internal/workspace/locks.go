@@ -0,0 +1,31 @@
+package workspace
+
+import (
+	"path/filepath"
+	"sync"
+)
+
+// repoLocks serializes changes to one repository across concurrent
+// Resolve calls, so two worktrees are never added to it at once. Locks are
+// keyed by the directory a repository is cloned to and shared by every
+// Resolver in the process.
+var repoLocks = &keyedMutex{locks: map[string]*sync.Mutex{}}
+
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+// lock blocks until key is free and returns the function that releases it.
+func (k *keyedMutex) lock(key string) func() {
+	key = filepath.Clean(key)
+	k.mu.Lock()
+	lock, ok := k.locks[key]
+	if !ok {
+		lock = &sync.Mutex{}
+		k.locks[key] = lock
+	}
+	k.mu.Unlock()
+	lock.Lock()
+	return lock.Unlock
+}
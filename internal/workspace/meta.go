@@ -0,0 +1,209 @@
+package workspace
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BradyPlanden/prt/internal/github"
+)
+
+// Worktree modes recorded in WorktreeRecord.Mode.
+const (
+	ModePersistent = "persistent"
+	ModeTemp       = "temp"
+)
+
+// WorktreeRecord is the metadata prt keeps for each worktree it creates.
+type WorktreeRecord struct {
+	Path    string `json:"path"`
+	RepoDir string `json:"repo_dir"`
+	URL     string `json:"url"`
+	Host    string `json:"host,omitempty"`
+	Number  int    `json:"number"`
+	Title   string `json:"title,omitempty"`
+	// BaseRepo and HeadRepo are owner/name; HeadRepo is empty when the
+	// head repository has been deleted.
+	BaseRepo string `json:"base_repo,omitempty"`
+	BaseRef  string `json:"base_ref,omitempty"`
+	HeadRepo string `json:"head_repo,omitempty"`
+	HeadRef  string `json:"head_ref,omitempty"`
+	Mode     string `json:"mode,omitempty"`
+	// Commit is set for worktrees opened from a /commits/<sha> link.
+	Commit     string    `json:"commit,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	// HeadSHA is the worktree HEAD when prt checked it out. Later local
+	// commits descend from it, so it is reachable from the PR head unless
+	// the PR was force-pushed.
+	HeadSHA string `json:"head_sha,omitempty"`
+}
+
+// ReadWorktreeRecord returns the metadata recorded for the prt worktree at
+// worktreePath. Worktrees that only have a legacy last-used marker yield a
+// partial record built from it. ok is false when nothing is recorded.
+func ReadWorktreeRecord(worktreePath string) (WorktreeRecord, bool, error) {
+	return readWorktreeRecord(filepath.Dir(worktreePath), worktreePath)
+}
+
+// worktreeRecordPath returns the metadata file for worktreePath. Temp
+// worktrees keep metadata under the temp dir and persistent worktrees under
+// their <repo>-worktrees directory; both are the worktree's parent.
+func worktreeRecordPath(metaRoot string, worktreePath string) string {
+	return filepath.Join(metaRoot, ".prt-meta", "worktrees", worktreeMetaName(worktreePath)+".json")
+}
+
+// worktreeMarkerPath returns the legacy last-used marker for worktreePath,
+// whose mtime is the last-used time and whose content, if any, is the PR URL.
+func worktreeMarkerPath(metaRoot string, worktreePath string) string {
+	return filepath.Join(metaRoot, ".prt-meta", "last-used", worktreeMetaName(worktreePath)+".last-used")
+}
+
+func worktreeMetaName(worktreePath string) string {
+	sum := sha256.Sum256([]byte(worktreePath))
+	return fmt.Sprintf("%s-%x", filepath.Base(worktreePath), sum[:8])
+}
+
+func readWorktreeRecord(metaRoot string, worktreePath string) (WorktreeRecord, bool, error) {
+	data, err := os.ReadFile(worktreeRecordPath(metaRoot, worktreePath))
+	if err == nil {
+		var record WorktreeRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			return WorktreeRecord{}, false, fmt.Errorf("parse worktree metadata: %w", err)
+		}
+		return record, true, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return WorktreeRecord{}, false, fmt.Errorf("read worktree metadata: %w", err)
+	}
+	return readLegacyMarker(metaRoot, worktreePath)
+}
+
+func readLegacyMarker(metaRoot string, worktreePath string) (WorktreeRecord, bool, error) {
+	path := worktreeMarkerPath(metaRoot, worktreePath)
+	info, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return WorktreeRecord{}, false, nil
+		}
+		return WorktreeRecord{}, false, fmt.Errorf("stat usage marker: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return WorktreeRecord{}, false, fmt.Errorf("read usage marker: %w", err)
+	}
+	return WorktreeRecord{
+		Path:       worktreePath,
+		URL:        strings.TrimSpace(string(data)),
+		LastUsedAt: info.ModTime(),
+	}, true, nil
+}
+
+func writeWorktreeRecord(metaRoot string, record WorktreeRecord) error {
+	path := worktreeRecordPath(metaRoot, record.Path)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create worktree metadata directory: %w", err)
+	}
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode worktree metadata: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write worktree metadata: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("write worktree metadata: %w", err)
+	}
+
+	// The record supersedes any legacy marker.
+	if err := os.Remove(worktreeMarkerPath(metaRoot, record.Path)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove usage marker: %w", err)
+	}
+	return nil
+}
+
+// recordWorktree creates or refreshes the metadata record for a resolved
+// worktree, migrating a legacy marker if one exists.
+func (r *Resolver) recordWorktree(ctx context.Context, metaRoot string, result Result, pr github.PRMetadata, mode string, commit string) error {
+	now := time.Now()
+	record, ok, err := readWorktreeRecord(metaRoot, result.Path)
+	if err != nil {
+		return err
+	}
+	if !ok || record.CreatedAt.IsZero() {
+		record.CreatedAt = now
+		if ok && !record.LastUsedAt.IsZero() {
+			record.CreatedAt = record.LastUsedAt
+		}
+	}
+
+	record.Path = result.Path
+	record.RepoDir = result.RepoDir
+	record.URL = prURL(pr)
+	record.Host = pr.BaseRepo.HostName()
+	record.Number = pr.Number
+	record.Title = pr.Title
+	record.BaseRepo = repoFullName(pr.BaseRepo)
+	record.BaseRef = pr.BaseRef
+	record.HeadRepo = repoFullName(pr.HeadRepo)
+	record.HeadRef = pr.HeadRef
+	record.Mode = mode
+	record.Commit = commit
+	record.LastUsedAt = now
+	if !result.Reused || result.Updated || record.HeadSHA == "" {
+		if sha, err := r.git.RevParse(ctx, result.Path, "HEAD"); err == nil {
+			record.HeadSHA = sha
+		}
+	}
+
+	return writeWorktreeRecord(metaRoot, record)
+}
+
+func worktreeLastUsedAt(metaRoot string, worktreePath string) (time.Time, bool, error) {
+	record, ok, err := readWorktreeRecord(metaRoot, worktreePath)
+	if err != nil || !ok || record.LastUsedAt.IsZero() {
+		return time.Time{}, false, err
+	}
+	return record.LastUsedAt, true, nil
+}
+
+// worktreePRURL returns the PR URL recorded for worktreePath, or "" when
+// none is recorded.
+func worktreePRURL(metaRoot string, worktreePath string) (string, error) {
+	record, _, err := readWorktreeRecord(metaRoot, worktreePath)
+	return record.URL, err
+}
+
+// removeWorktreeMarker removes the metadata record and any legacy marker.
+func removeWorktreeMarker(metaRoot string, worktreePath string) error {
+	for _, path := range []string{worktreeRecordPath(metaRoot, worktreePath), worktreeMarkerPath(metaRoot, worktreePath)} {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("remove worktree metadata: %w", err)
+		}
+	}
+	return nil
+}
+
+// prURL returns the PR web URL, deriving it from the base repository when
+// the metadata does not include one.
+func prURL(pr github.PRMetadata) string {
+	if pr.URL != "" {
+		return pr.URL
+	}
+	ref := github.PRRef{Host: pr.BaseRepo.HostName(), Owner: pr.BaseRepo.Owner, Repo: pr.BaseRepo.Name, Number: pr.Number}
+	return ref.URL()
+}
+
+func repoFullName(repo github.Repository) string {
+	if repo.Owner == "" || repo.Name == "" {
+		return ""
+	}
+	return repo.Owner + "/" + repo.Name
+}
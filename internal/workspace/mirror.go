@@ -0,0 +1,32 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/BradyPlanden/prt/internal/git"
+	"github.com/BradyPlanden/prt/internal/github"
+)
+
+// MirrorCache provides local mirrors for new clones to copy objects from.
+type MirrorCache interface {
+	// Ensure creates or updates the mirror of repo and returns its path.
+	Ensure(ctx context.Context, repo github.Repository) (string, error)
+}
+
+// useMirror points a new clone of repo at its cached mirror, if a cache is
+// configured and clone has no other reference. The clone copies the objects
+// it needs (--dissociate), so pruning the cache never breaks it. When the
+// mirror cannot be updated, the clone falls back to the network.
+func (r *Resolver) useMirror(ctx context.Context, repo github.Repository, clone *git.CloneOptions) []string {
+	if r.cache == nil || clone.Reference != "" {
+		return nil
+	}
+	mirror, err := r.cache.Ensure(ctx, repo)
+	if err != nil {
+		return []string{fmt.Sprintf("could not update mirror cache; cloning from the network: %v", err)}
+	}
+	clone.Reference = mirror
+	clone.Dissociate = true
+	return nil
+}
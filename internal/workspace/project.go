@@ -0,0 +1,82 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/BradyPlanden/prt/internal/config"
+	"github.com/BradyPlanden/prt/internal/github"
+)
+
+const hookSetup = "setup"
+
+// readProject reads .prt.yaml from the PR's base branch in repoDir rather
+// than from the checked-out worktree. Trust is granted per base repository,
+// so reading the PR head would let any PR to a trusted repository choose
+// the commands it is opened with. It returns nil when the base branch has
+// no project file.
+func (r *Resolver) readProject(ctx context.Context, repoDir string, pr github.PRMetadata) (*config.Project, []string) {
+	if pr.BaseRef == "" {
+		return nil, nil
+	}
+	data, ok, err := r.git.FileAtRev(ctx, repoDir, "origin/"+pr.BaseRef, config.ProjectFile)
+	if err != nil {
+		return nil, []string{fmt.Sprintf("could not read %s from %s: %v", config.ProjectFile, pr.BaseRef, err)}
+	}
+	if !ok {
+		return nil, nil
+	}
+	project, err := config.ParseProject([]byte(data))
+	if err != nil {
+		return nil, []string{fmt.Sprintf("ignoring %s: %v", config.ProjectFile, err)}
+	}
+	return &project, nil
+}
+
+// trustProject drops result.Project unless the base repository is in
+// cfg.TrustedRepos, since its copy, sparse, and submodules settings act on
+// the machine as much as its setup commands do. It warns only for new
+// worktrees, the only ones a project file would have been applied to.
+func trustProject(cfg config.Config, pr github.PRMetadata, result *Result) []string {
+	if result.Project == nil {
+		return nil
+	}
+	repo := repoFullName(pr.BaseRepo)
+	if config.IsTrusted(cfg.TrustedRepos, repo) {
+		return nil
+	}
+	result.Project = nil
+	if result.Reused {
+		return nil
+	}
+	return []string{fmt.Sprintf("ignoring %s: %s is not in trusted_repos", config.ProjectFile, repo)}
+}
+
+// setUpWorktree prepares a newly created worktree: the .prt.yaml sparse
+// checkout, then files from .prt.yaml copy and copy_files, then .prt.yaml
+// setup commands. result.Project is only set for trusted repositories; see
+// trustProject. Problems are returned as warnings.
+func (r *Resolver) setUpWorktree(ctx context.Context, cfg config.Config, pr github.PRMetadata, result Result, mode string) []string {
+	if result.Reused {
+		return nil
+	}
+	project := result.Project
+	if project == nil {
+		project = &config.Project{}
+	}
+
+	var warnings []string
+	if len(project.Sparse) > 0 {
+		if err := r.git.SparseCheckoutSet(ctx, result.Path, project.Sparse); err != nil {
+			warnings = append(warnings, fmt.Sprintf("could not set up sparse checkout: %v", err))
+		}
+	}
+	warnings = append(warnings, r.copyFiles(ctx, cfg, pr, result, mode, project.Copy)...)
+	env := hookEnv(pr, result, mode, hookSetup)
+	for _, command := range project.Setup {
+		if err := r.runHook(ctx, cfg.Hooks.Timeout, result.Path, command, env); err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s setup command %q failed: %v", config.ProjectFile, command, err))
+		}
+	}
+	return warnings
+}
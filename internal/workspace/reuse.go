@@ -0,0 +1,153 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/BradyPlanden/prt/internal/github"
+)
+
+// ForcePushPolicy decides how a reused worktree reacts when its branch is
+// no longer contained in the fetched PR head, usually after a force-push.
+type ForcePushPolicy string
+
+const (
+	// ForcePushWarn leaves the worktree alone and reports a warning.
+	ForcePushWarn ForcePushPolicy = "warn"
+	// ForcePushReset hard-resets the worktree to the new PR head.
+	ForcePushReset ForcePushPolicy = "reset"
+	// ForcePushPrompt asks before resetting.
+	ForcePushPrompt ForcePushPolicy = "prompt"
+)
+
+// prHeadsBeforeFetch records where the PR's remote-tracking refs point
+// before they are refreshed, keyed by checkout start point.
+func (r *Resolver) prHeadsBeforeFetch(ctx context.Context, path string, pr github.PRMetadata) map[string]string {
+	heads := make(map[string]string)
+	for _, target := range []prCheckoutTarget{primaryCheckoutTarget(pr), pullRefCheckoutTarget(pr)} {
+		if sha, err := r.git.RevParse(ctx, path, target.StartPoint); err == nil {
+			heads[target.StartPoint] = sha
+		}
+	}
+	return heads
+}
+
+// updateReusedWorktree brings a reused worktree in line with the freshly
+// fetched target: it fast-forwards when opts.Update is set and applies
+// opts.OnForcePush when HEAD is no longer contained in the PR head.
+// previousHead is the PR head before the fetch, or "" when unknown. It
+// reports whether the worktree HEAD moved.
+func (r *Resolver) updateReusedWorktree(ctx context.Context, path string, target prCheckoutTarget, previousHead string, opts Options) (bool, []string, error) {
+	newHead, err := r.git.RevParse(ctx, path, target.StartPoint)
+	if err != nil {
+		return false, nil, nil
+	}
+	contained, err := r.git.IsAncestor(ctx, path, "HEAD", newHead)
+	if err != nil {
+		return false, []string{fmt.Sprintf("could not compare worktree with PR head: %v", err)}, nil
+	}
+	if contained {
+		if !opts.Update {
+			return false, nil, nil
+		}
+		return r.fastForward(ctx, path, newHead)
+	}
+	return r.handleForcePush(ctx, path, newHead, previousHead, opts)
+}
+
+// fastForward moves a clean worktree forward to newHead, which must contain
+// HEAD.
+func (r *Resolver) fastForward(ctx context.Context, path string, newHead string) (bool, []string, error) {
+	head, err := r.git.RevParse(ctx, path, "HEAD")
+	if err != nil {
+		return false, nil, err
+	}
+	if head == newHead {
+		return false, nil, nil
+	}
+	dirty, err := r.git.IsWorktreeDirty(ctx, path)
+	if err != nil {
+		return false, nil, err
+	}
+	if dirty {
+		return false, []string{fmt.Sprintf("not updating to PR head %s because the worktree has uncommitted changes", ShortSHA(newHead))}, nil
+	}
+	if err := r.git.MergeFastForward(ctx, path, newHead); err != nil {
+		return false, []string{fmt.Sprintf("could not fast-forward to PR head %s: %v", ShortSHA(newHead), err)}, nil
+	}
+	return true, []string{fmt.Sprintf("updated worktree from %s to PR head %s", ShortSHA(head), ShortSHA(newHead))}, nil
+}
+
+// handleForcePush applies opts.OnForcePush when the worktree HEAD is not an
+// ancestor of newHead. It reports whether the worktree was reset.
+func (r *Resolver) handleForcePush(ctx context.Context, path string, newHead string, previousHead string, opts Options) (bool, []string, error) {
+	head, err := r.git.RevParse(ctx, path, "HEAD")
+	if err != nil {
+		return false, nil, err
+	}
+	summary := fmt.Sprintf("PR head %s does not contain the worktree's %s (force-pushed?)", ShortSHA(newHead), ShortSHA(head))
+
+	policy := opts.OnForcePush
+	if policy == ForcePushPrompt {
+		if r.confirm == nil {
+			policy = ForcePushWarn
+		} else {
+			ok, err := r.confirm(fmt.Sprintf("%s. Reset %s to the new PR head?", summary, path))
+			if err != nil {
+				return false, nil, err
+			}
+			if !ok {
+				return false, []string{summary + "; kept the existing checkout"}, nil
+			}
+			policy = ForcePushReset
+		}
+	}
+	if policy != ForcePushReset {
+		if opts.Update {
+			return false, []string{summary + "; not fast-forwarding because the branch has diverged (set on_force_push: reset to update)"}, nil
+		}
+		return false, []string{summary + "; kept the existing checkout (set on_force_push: reset to update)"}, nil
+	}
+
+	if !opts.Force {
+		dirty, err := r.git.IsWorktreeDirty(ctx, path)
+		if err != nil {
+			return false, nil, err
+		}
+		if dirty {
+			return false, []string{summary + "; not resetting because the worktree has uncommitted changes (use --force to discard them)"}, nil
+		}
+		// Commits from the PR's earlier history are not local work: exclude
+		// both the pre-fetch PR head and the commit prt checked out.
+		var known []string
+		if previousHead != "" {
+			known = append(known, previousHead)
+		}
+		if record, _, err := ReadWorktreeRecord(path); err == nil && record.HeadSHA != "" {
+			known = append(known, record.HeadSHA)
+		}
+		if len(known) == 0 {
+			return false, []string{summary + "; not resetting because local commits could not be ruled out (use --force to reset anyway)"}, nil
+		}
+		local, err := r.git.CountCommits(ctx, path, "HEAD", known...)
+		if err != nil {
+			return false, nil, err
+		}
+		if local > 0 {
+			return false, []string{fmt.Sprintf("%s; not resetting because the worktree has %d local commit(s) (use --force to discard them)", summary, local)}, nil
+		}
+	}
+
+	if err := r.git.ResetHard(ctx, path, newHead); err != nil {
+		return false, nil, err
+	}
+	return true, []string{fmt.Sprintf("PR was force-pushed; reset worktree from %s to %s", ShortSHA(head), ShortSHA(newHead))}, nil
+}
+
+// ShortSHA abbreviates sha to 12 characters for messages.
+func ShortSHA(sha string) string {
+	if len(sha) > 12 {
+		return sha[:12]
+	}
+	return sha
+}
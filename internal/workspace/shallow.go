@@ -0,0 +1,100 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/BradyPlanden/prt/internal/config"
+	"github.com/BradyPlanden/prt/internal/git"
+	"github.com/BradyPlanden/prt/internal/github"
+)
+
+const (
+	// defaultShallowDepth limits fetches into a shallow repository when
+	// clone.depth is not set (the clone was made with other settings).
+	defaultShallowDepth = 50
+	// deepenStep is the first --deepen used to look for a merge-base; it
+	// doubles each round, and after maxDeepenRounds the repository is
+	// unshallowed.
+	deepenStep      = 100
+	maxDeepenRounds = 5
+)
+
+func cloneOptions(clone config.Clone) git.CloneOptions {
+	return git.CloneOptions{Depth: clone.Depth, Filter: clone.Filter, SingleBranch: clone.SingleBranch}
+}
+
+// shallowFetchDepth returns the --depth for fetches into repoDir: 0 when it
+// has full history, so fetches are unlimited.
+func (r *Resolver) shallowFetchDepth(ctx context.Context, repoDir string, clone config.Clone) int {
+	shallow, err := r.git.IsShallow(ctx, repoDir)
+	if err != nil || !shallow {
+		return 0
+	}
+	if clone.Depth > 0 {
+		return clone.Depth
+	}
+	return defaultShallowDepth
+}
+
+// fetchRef fetches refspec, limited to depth commits when depth is set.
+func fetchRef(ctx context.Context, client GitClient, repoDir string, remote string, refspec string, depth int) error {
+	if depth > 0 {
+		return client.FetchShallow(ctx, repoDir, remote, refspec, git.ShallowFetch{Depth: depth})
+	}
+	return client.Fetch(ctx, repoDir, remote, refspec)
+}
+
+// fetchBase fetches the PR's base branch from origin.
+func fetchBase(ctx context.Context, client GitClient, repoDir string, pr github.PRMetadata, depth int) error {
+	if depth > 0 {
+		return fetchRef(ctx, client, repoDir, "origin", git.BranchRefspec("origin", pr.BaseRef), depth)
+	}
+	return client.FetchBranch(ctx, repoDir, "origin", pr.BaseRef)
+}
+
+// deepenToMergeBase extends the history of a shallow repository until the
+// PR head and its base branch share a commit, so diffs against the base
+// work. depth is the shallow fetch depth; 0 means repoDir is not shallow.
+func (r *Resolver) deepenToMergeBase(ctx context.Context, repoDir string, pr github.PRMetadata, target prCheckoutTarget, depth int) []string {
+	if depth == 0 || pr.BaseRef == "" {
+		return nil
+	}
+	base := "origin/" + pr.BaseRef
+	refs := []prCheckoutTarget{
+		{Remote: "origin", Refspec: git.BranchRefspec("origin", pr.BaseRef)},
+		target,
+	}
+	deepen := func(shallow git.ShallowFetch) error {
+		for _, ref := range refs {
+			if shallow.Unshallow {
+				// --unshallow fails once the repository is complete.
+				if still, err := r.git.IsShallow(ctx, repoDir); err == nil && !still {
+					return nil
+				}
+			}
+			if err := r.git.FetchShallow(ctx, repoDir, ref.Remote, ref.Refspec, shallow); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	step := deepenStep
+	for range maxDeepenRounds {
+		if mergeBase, err := r.git.MergeBase(ctx, repoDir, base, target.StartPoint); err == nil && mergeBase != "" {
+			return nil
+		}
+		if err := deepen(git.ShallowFetch{Deepen: step}); err != nil {
+			return []string{fmt.Sprintf("could not deepen shallow clone to find the merge-base with %s: %v", base, err)}
+		}
+		step *= 2
+	}
+	if mergeBase, err := r.git.MergeBase(ctx, repoDir, base, target.StartPoint); err == nil && mergeBase != "" {
+		return nil
+	}
+	if err := deepen(git.ShallowFetch{Unshallow: true}); err != nil {
+		return []string{fmt.Sprintf("could not unshallow clone to find the merge-base with %s: %v", base, err)}
+	}
+	return nil
+}
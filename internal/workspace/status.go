@@ -0,0 +1,141 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/BradyPlanden/prt/internal/github"
+)
+
+// StatusOptions configures Resolver.Status.
+type StatusOptions struct {
+	// Fetch refreshes the upstream and base branches before comparing.
+	Fetch bool
+}
+
+// WorktreeStatus describes how a PR worktree differs from the remote PR.
+type WorktreeStatus struct {
+	Path   string
+	Branch string
+	// Upstream is the remote-tracking ref the worktree is compared against;
+	// it is empty when none could be determined.
+	Upstream string
+	Ahead    int
+	Behind   int
+	// ForcePushed reports that the commit checked out when the worktree was
+	// created is no longer reachable from Upstream.
+	ForcePushed bool
+	CheckoutSHA string
+	// Base is origin/<base branch>; BaseBehind counts commits on Base that
+	// are not in the worktree.
+	Base       string
+	BaseBehind int
+	MergeBase  string
+	DirtyFiles []string
+	Warnings   []string
+}
+
+// Status compares the worktree at path with its upstream PR branch and the
+// PR's base branch.
+func (r *Resolver) Status(ctx context.Context, path string, opts StatusOptions) (WorktreeStatus, error) {
+	status := WorktreeStatus{Path: path}
+	record, _, err := ReadWorktreeRecord(path)
+	if err != nil {
+		return WorktreeStatus{}, err
+	}
+
+	status.Branch, err = r.git.CurrentBranch(ctx, path)
+	if err != nil {
+		return WorktreeStatus{}, err
+	}
+	status.Upstream, err = r.git.Upstream(ctx, path)
+	if err != nil {
+		return WorktreeStatus{}, err
+	}
+	if status.Upstream == "" && record.Number > 0 {
+		// Worktrees checked out from refs/pull/N/head have no upstream.
+		pullRef := pullRefCheckoutTarget(github.PRMetadata{Number: record.Number})
+		if opts.Fetch {
+			if err := r.git.Fetch(ctx, path, pullRef.Remote, pullRef.Refspec); err != nil {
+				status.Warnings = append(status.Warnings, fmt.Sprintf("could not fetch %s: %v", pullRef.StartPoint, err))
+			}
+		}
+		if _, err := r.git.RevParse(ctx, path, pullRef.StartPoint); err == nil {
+			status.Upstream = pullRef.StartPoint
+		}
+	} else if status.Upstream != "" && opts.Fetch {
+		if err := r.fetchUpstream(ctx, path, status.Branch, status.Upstream); err != nil {
+			status.Warnings = append(status.Warnings, fmt.Sprintf("could not fetch %s (working offline?): %v", status.Upstream, err))
+		}
+	}
+
+	if status.Upstream != "" {
+		status.Ahead, status.Behind, err = r.git.AheadBehind(ctx, path, "HEAD", status.Upstream)
+		if err != nil {
+			return WorktreeStatus{}, err
+		}
+		if record.HeadSHA != "" {
+			status.CheckoutSHA = record.HeadSHA
+			reachable, err := r.git.IsAncestor(ctx, path, record.HeadSHA, status.Upstream)
+			if err != nil {
+				status.Warnings = append(status.Warnings, fmt.Sprintf("could not check for force-push: %v", err))
+			} else {
+				status.ForcePushed = !reachable
+			}
+		}
+	}
+
+	if record.BaseRef != "" {
+		if opts.Fetch {
+			if err := r.git.FetchBranch(ctx, path, "origin", record.BaseRef); err != nil {
+				status.Warnings = append(status.Warnings, fmt.Sprintf("could not fetch base branch %s (working offline?): %v", record.BaseRef, err))
+			}
+		}
+		base := "origin/" + record.BaseRef
+		if _, behind, err := r.git.AheadBehind(ctx, path, "HEAD", base); err != nil {
+			status.Warnings = append(status.Warnings, fmt.Sprintf("could not compare with %s: %v", base, err))
+		} else {
+			status.Base = base
+			status.BaseBehind = behind
+			if mergeBase, err := r.git.MergeBase(ctx, path, "HEAD", base); err == nil {
+				status.MergeBase = mergeBase
+			}
+		}
+	}
+
+	status.DirtyFiles, err = r.git.ChangedFiles(ctx, path)
+	if err != nil {
+		return WorktreeStatus{}, err
+	}
+	return status, nil
+}
+
+// PRFiles returns the files the PR at path changes relative to its base
+// branch, in git's path order. Deleted files are omitted.
+func (r *Resolver) PRFiles(ctx context.Context, path string) ([]string, error) {
+	record, _, err := ReadWorktreeRecord(path)
+	if err != nil {
+		return nil, err
+	}
+	if record.BaseRef == "" {
+		return nil, fmt.Errorf("no base branch recorded for %s", path)
+	}
+	return r.git.DiffNames(ctx, path, "origin/"+record.BaseRef, "HEAD")
+}
+
+// fetchUpstream fetches the branch behind upstream (<remote>/<branch>) from
+// its remote. Remote names may contain slashes, so the remote is looked up
+// from the branch config rather than split from upstream.
+func (r *Resolver) fetchUpstream(ctx context.Context, path string, branch string, upstream string) error {
+	upstreams, err := r.git.BranchUpstreamRemotes(ctx, path)
+	if err != nil {
+		return err
+	}
+	remote := upstreams[branch]
+	remoteBranch, ok := strings.CutPrefix(upstream, remote+"/")
+	if remote == "" || !ok {
+		return fmt.Errorf("cannot determine remote for %s", upstream)
+	}
+	return r.git.FetchBranch(ctx, path, remote, remoteBranch)
+}
@@ -0,0 +1,88 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	"github.com/BradyPlanden/prt/internal/config"
+	"github.com/BradyPlanden/prt/internal/git"
+	"github.com/BradyPlanden/prt/internal/github"
+)
+
+// updateSubmodules initializes submodules in result.Path (best-effort) as
+// the effective submodules setting asks. Submodules the main checkout has
+// already cloned borrow its objects with --reference.
+func (r *Resolver) updateSubmodules(ctx context.Context, cfg config.Config, pr github.PRMetadata, result Result, mode string, opts Options) []string {
+	if opts.NoSubmodules {
+		return nil
+	}
+	settings := cfg.SubmodulesFor(repoFullName(pr.BaseRepo), result.Project)
+	if settings.Policy == config.SubmodulesNone {
+		return nil
+	}
+	if settings.Policy == config.SubmodulesList && len(settings.Paths) == 0 {
+		return []string{"not initializing submodules: the list policy needs paths"}
+	}
+
+	update := git.SubmoduleOptions{Recursive: true, Depth: settings.Depth, Jobs: settings.Jobs}
+	if settings.Policy == config.SubmodulesShallow && update.Depth == 0 {
+		update.Depth = 1
+	}
+	if settings.Policy == config.SubmodulesList {
+		update.Paths = settings.Paths
+	}
+
+	var warnings []string
+	if modulesDir := r.mainModulesDir(ctx, cfg, pr, result, mode); modulesDir != "" {
+		// Submodules with a reference are updated one at a time, since
+		// --reference applies to every submodule in the command.
+		submodules, err := r.git.Submodules(ctx, result.Path)
+		if err == nil {
+			var referenced, rest []string
+			for _, submodule := range submodules {
+				if len(update.Paths) > 0 && !slices.Contains(update.Paths, submodule.Path) {
+					continue
+				}
+				reference := filepath.Join(modulesDir, filepath.FromSlash(submodule.Name))
+				if !isDir(reference) {
+					rest = append(rest, submodule.Path)
+					continue
+				}
+				single := update
+				single.Paths = []string{submodule.Path}
+				single.Reference = reference
+				if err := r.git.SubmoduleUpdate(ctx, result.Path, single); err != nil {
+					warnings = append(warnings, fmt.Sprintf("could not initialize submodule %s: %v", submodule.Path, err))
+				}
+				referenced = append(referenced, submodule.Path)
+			}
+			if len(referenced) > 0 {
+				if len(rest) == 0 {
+					return warnings
+				}
+				update.Paths = rest
+			}
+		}
+	}
+
+	if err := r.git.SubmoduleUpdate(ctx, result.Path, update); err != nil {
+		warnings = append(warnings, fmt.Sprintf("could not initialize submodules: %v", err))
+	}
+	return warnings
+}
+
+// mainModulesDir returns the main checkout's .git/modules directory, where
+// it keeps its submodule clones, or "" when there is none.
+func (r *Resolver) mainModulesDir(ctx context.Context, cfg config.Config, pr github.PRMetadata, result Result, mode string) string {
+	mainDir, _ := r.mainCheckoutDir(ctx, cfg, pr, result, mode)
+	if mainDir == "" {
+		return ""
+	}
+	modulesDir := filepath.Join(mainDir, ".git", "modules")
+	if !isDir(modulesDir) {
+		return ""
+	}
+	return modulesDir
+}
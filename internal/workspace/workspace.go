@@ -2,7 +2,6 @@ package workspace
 
 import (
 	"context"
-	"crypto/sha256"
 	"errors"
 	"fmt"
 	"net/url"
@@ -19,24 +18,56 @@ import (
 // Options controls resolver behavior for temp versus persistent worktrees.
 type Options struct {
 	Temp bool
+	// Commit checks out a specific PR commit instead of the PR head.
+	Commit string
+	// CommitMode selects how Commit is checked out.
+	CommitMode CommitMode
+	// OnForcePush decides what happens when a reused worktree's branch is
+	// no longer contained in the fetched PR head. The zero value warns.
+	OnForcePush ForcePushPolicy
+	// Force allows resets that discard uncommitted changes or local commits.
+	Force bool
+	// Update fast-forwards a clean reused worktree to the fetched PR head.
+	Update bool
+	// NoSubmodules skips submodule initialization whatever the config says.
+	NoSubmodules bool
 }
 
+// CommitMode describes how a specific PR commit is checked out.
+type CommitMode string
+
+const (
+	// CommitModeDetach checks the commit out with a detached HEAD.
+	CommitModeDetach CommitMode = "detach"
+	// CommitModeBranch checks the commit out on a pr/<N>/commit-<sha> branch.
+	CommitModeBranch CommitMode = "branch"
+)
+
 // Result is the resolved workspace location and related metadata.
 type Result struct {
-	Path     string
-	RepoDir  string
-	Reused   bool
+	Path    string
+	RepoDir string
+	Reused  bool
+	// Updated reports that a reused worktree was moved to the latest PR head.
+	Updated  bool
 	Warnings []string
+	// Project is the base branch's .prt.yaml, or nil when there is none or
+	// the base repository is not in trusted_repos.
+	Project *config.Project
 }
 
-// CleanResult describes one removed or removable worktree path.
+// CleanResult describes one removed or removable worktree path. Branch or
+// Remote is set instead when the result is for a branch or remote of the
+// repository at Path.
 type CleanResult struct {
 	Path   string
 	Action CleanAction
 	Reason string
+	Branch string
+	Remote string
 }
 
-// CleanAction describes the outcome for a temp worktree cleanup candidate.
+// CleanAction describes the outcome for a worktree cleanup candidate.
 type CleanAction string
 
 const (
@@ -54,8 +85,11 @@ const (
 
 // Resolver maps PR metadata to persistent or temporary worktrees.
 type Resolver struct {
-	git    GitClient
-	logger Logger
+	git     GitClient
+	logger  Logger
+	confirm ConfirmFunc
+	hooks   HookRunner
+	cache   MirrorCache
 }
 
 // Logger provides warning output hooks.
@@ -66,16 +100,33 @@ type Logger interface {
 // ResolverOptions configures optional resolver behavior.
 type ResolverOptions struct {
 	Logger Logger
+	// Confirm asks the user a yes/no question. When nil, prompts fall back
+	// to the non-destructive choice.
+	Confirm ConfirmFunc
+	// Hooks runs configured post_create and post_reuse hooks; defaults to
+	// ShellHookRunner.
+	Hooks HookRunner
+	// Cache supplies mirrors for first-time clones to copy objects from;
+	// nil clones straight from the network.
+	Cache MirrorCache
 }
 
+// ConfirmFunc asks the user to confirm question.
+type ConfirmFunc func(question string) (bool, error)
+
 // GitClient defines the git operations required by Resolver.
 type GitClient interface {
 	IsGitRepo(ctx context.Context, repoDir string) (bool, error)
-	Clone(ctx context.Context, url string, dest string) error
-	CloneBare(ctx context.Context, url string, dest string, depth int) error
+	Clone(ctx context.Context, url string, dest string, opts git.CloneOptions) error
+	CloneBare(ctx context.Context, url string, dest string, opts git.CloneOptions) error
+	IsShallow(ctx context.Context, repoDir string) (bool, error)
+	IsPartialClone(ctx context.Context, repoDir string) (bool, error)
+	RefetchAll(ctx context.Context, repoDir string) error
 	Fetch(ctx context.Context, repoDir string, remote string, refspec string) error
+	FetchShallow(ctx context.Context, repoDir string, remote string, refspec string, shallow git.ShallowFetch) error
 	FetchBranch(ctx context.Context, repoDir string, remote string, branch string) error
-	SubmoduleUpdate(ctx context.Context, repoDir string) error
+	SubmoduleUpdate(ctx context.Context, repoDir string, opts git.SubmoduleOptions) error
+	Submodules(ctx context.Context, repoDir string) ([]git.Submodule, error)
 	WorktreeAdd(ctx context.Context, repoDir string, worktreePath string, branch string) error
 	WorktreeRemove(ctx context.Context, repoDir string, worktreePath string, force bool) error
 	WorktreeList(ctx context.Context, repoDir string) ([]git.Worktree, error)
@@ -90,64 +141,159 @@ type GitClient interface {
 	WorktreeAddBranch(ctx context.Context, repoDir string, worktreePath string, branch string, startPoint string, force bool) error
 	IsWorktreeDirty(ctx context.Context, repoDir string) (bool, error)
 	WorktreePrune(ctx context.Context, repoDir string) error
+	WorktreeAddDetached(ctx context.Context, repoDir string, worktreePath string, commit string) error
+	RevParse(ctx context.Context, repoDir string, rev string) (string, error)
+	Remotes(ctx context.Context, repoDir string) ([]string, error)
+	RemoveRemote(ctx context.Context, repoDir string, name string) error
+	DeleteBranch(ctx context.Context, repoDir string, branch string, force bool) error
+	BranchUpstreamRemotes(ctx context.Context, repoDir string) (map[string]string, error)
+	CurrentBranch(ctx context.Context, repoDir string) (string, error)
+	Upstream(ctx context.Context, repoDir string) (string, error)
+	AheadBehind(ctx context.Context, repoDir string, local string, upstream string) (int, int, error)
+	MergeBase(ctx context.Context, repoDir string, a string, b string) (string, error)
+	IsAncestor(ctx context.Context, repoDir string, ancestor string, descendant string) (bool, error)
+	ChangedFiles(ctx context.Context, repoDir string) ([]string, error)
+	ResetHard(ctx context.Context, repoDir string, rev string) error
+	CountCommits(ctx context.Context, repoDir string, rev string, exclude ...string) (int, error)
+	MergeFastForward(ctx context.Context, repoDir string, rev string) error
+	DiffNames(ctx context.Context, repoDir string, base string, head string) ([]string, error)
+	FileAtRev(ctx context.Context, repoDir string, rev string, path string) (string, bool, error)
+	SparseCheckoutSet(ctx context.Context, repoDir string, dirs []string) error
 }
 
 // NewResolver constructs a Resolver with the provided git client.
 func NewResolver(client GitClient, opts ResolverOptions) *Resolver {
-	return &Resolver{git: client, logger: opts.Logger}
+	hooks := opts.Hooks
+	if hooks == nil {
+		hooks = ShellHookRunner{}
+	}
+	return &Resolver{git: client, logger: opts.Logger, confirm: opts.Confirm, hooks: hooks, cache: opts.Cache}
 }
 
-// Resolve returns an existing or newly created worktree for a PR.
+// Resolve returns an existing or newly created worktree for a PR. Its
+// submodules are initialized and new worktrees are set up as the
+// repository's .prt.yaml asks, then the matching post_create or post_reuse
+// hooks run. It is safe to call concurrently: clones and worktree changes
+// are serialized per repository, while setup and hooks run in parallel.
 func (r *Resolver) Resolve(ctx context.Context, cfg config.Config, pr github.PRMetadata, opts Options) (Result, error) {
+	var result Result
+	var err error
+	mode := ModePersistent
 	if opts.Temp {
-		return r.resolveTemp(ctx, cfg, pr)
+		mode = ModeTemp
+		result, err = r.resolveTemp(ctx, cfg, pr, opts)
+	} else {
+		result, err = r.resolvePersistent(ctx, cfg, pr, opts)
 	}
-	return r.resolvePersistent(ctx, cfg, pr)
+	if err != nil {
+		return Result{}, err
+	}
+
+	setupWarnings := trustProject(cfg, pr, &result)
+	setupWarnings = append(setupWarnings, r.updateSubmodules(ctx, cfg, pr, result, mode, opts)...)
+	setupWarnings = append(setupWarnings, r.setUpWorktree(ctx, cfg, pr, result, mode)...)
+	setupWarnings = append(setupWarnings, r.runHooks(ctx, cfg.Hooks, pr, result, mode)...)
+	result.Warnings = append(result.Warnings, setupWarnings...)
+	r.logWarnings(setupWarnings)
+	return result, nil
 }
 
-func (r *Resolver) resolvePersistent(ctx context.Context, cfg config.Config, pr github.PRMetadata) (Result, error) {
+func (r *Resolver) resolvePersistent(ctx context.Context, cfg config.Config, pr github.PRMetadata, opts Options) (Result, error) {
+	// Lock before picking the directory: a clone in progress at the
+	// primary path must not send a second resolve to the alternate one.
+	defer repoLocks.lock(filepath.Join(cfg.ProjectsDir, pr.BaseRepo.Name))()
 	repoDir, err := resolveRepoDir(ctx, r.git, cfg.ProjectsDir, pr.BaseRepo, r.logger)
 	if err != nil {
 		return Result{}, err
 	}
 
-	if err := ensureRepo(ctx, r.git, repoDir, pr.BaseRepo.CloneURL); err != nil {
+	clone := cloneOptions(cfg.Clone)
+	var mirrorWarnings []string
+	if !pathExists(repoDir) {
+		mirrorWarnings = r.useMirror(ctx, pr.BaseRepo, &clone)
+		r.logWarnings(mirrorWarnings)
+	}
+	if err := ensureRepo(ctx, r.git, repoDir, pr.BaseRepo.CloneURL, clone); err != nil {
+		return Result{}, err
+	}
+	fetchDepth := r.shallowFetchDepth(ctx, repoDir, cfg.Clone)
+
+	worktreesDir := persistentWorktreesDir(repoDir)
+	worktreePath := filepath.Join(worktreesDir, worktreeName(pr))
+	var result Result
+	if opts.Commit != "" {
+		result, err = r.resolveCommitWorktree(ctx, repoDir, worktreePath, pr, opts, fetchDepth)
+	} else {
+		result, err = r.resolveWorktree(ctx, repoDir, worktreePath, pr, opts, fetchDepth, false)
+	}
+	if err != nil {
 		return Result{}, err
 	}
+	result.Warnings = append(mirrorWarnings, result.Warnings...)
+	if err := r.recordWorktree(ctx, worktreesDir, result, pr, ModePersistent, opts.Commit); err != nil {
+		warning := fmt.Sprintf("could not update worktree metadata: %v", err)
+		result.Warnings = append(result.Warnings, warning)
+		r.logWarnings([]string{warning})
+	}
+	return result, nil
+}
 
-	worktreePath := filepath.Join(repoDir+"-worktrees", worktreeName(pr))
-	return r.resolveWorktree(ctx, repoDir, worktreePath, pr, false)
+func persistentWorktreesDir(repoDir string) string {
+	return repoDir + "-worktrees"
 }
 
-func (r *Resolver) resolveTemp(ctx context.Context, cfg config.Config, pr github.PRMetadata) (Result, error) {
+func (r *Resolver) resolveTemp(ctx context.Context, cfg config.Config, pr github.PRMetadata, opts Options) (Result, error) {
 	if err := os.MkdirAll(cfg.TempDir, 0o755); err != nil {
 		return Result{}, fmt.Errorf("create temp dir: %w", err)
 	}
 
 	slug := repoSlug(pr.BaseRepo)
 	bareDir := filepath.Join(cfg.TempDir, slug+".git")
-
-	if err := ensureBareRepo(ctx, r.git, bareDir, pr.BaseRepo.CloneURL); err != nil {
+	defer repoLocks.lock(bareDir)()
+
+	// New bare clones borrow objects from the persistent clone, if any,
+	// or copy them from the mirror cache. Existing ones are checked for a
+	// reference that has since gone away.
+	clone := cloneOptions(cfg.Clone)
+	var cloneWarnings []string
+	if pathExists(bareDir) {
+		cloneWarnings = r.repairAlternates(ctx, bareDir)
+	} else {
+		clone.Reference = r.referenceRepoDir(ctx, cfg, pr)
+		cloneWarnings = r.useMirror(ctx, pr.BaseRepo, &clone)
+	}
+	r.logWarnings(cloneWarnings)
+	if err := ensureBareRepo(ctx, r.git, bareDir, pr.BaseRepo.CloneURL, clone); err != nil {
 		return Result{}, err
 	}
+	fetchDepth := r.shallowFetchDepth(ctx, bareDir, cfg.Clone)
 
 	worktreePath := filepath.Join(cfg.TempDir, slug+"-"+worktreeName(pr))
-	result, err := r.resolveWorktree(ctx, bareDir, worktreePath, pr, true)
+	var result Result
+	var err error
+	if opts.Commit != "" {
+		result, err = r.resolveCommitWorktree(ctx, bareDir, worktreePath, pr, opts, fetchDepth)
+	} else {
+		result, err = r.resolveWorktree(ctx, bareDir, worktreePath, pr, opts, fetchDepth, true)
+	}
 	if err != nil {
 		return Result{}, err
 	}
-	if err := touchTempWorktreeMarker(cfg.TempDir, result.Path); err != nil {
-		result.Warnings = append(result.Warnings, fmt.Sprintf("could not update temp worktree usage marker: %v", err))
-		r.logWarnings([]string{fmt.Sprintf("could not update temp worktree usage marker: %v", err)})
+	result.Warnings = append(cloneWarnings, result.Warnings...)
+	if err := r.recordWorktree(ctx, cfg.TempDir, result, pr, ModeTemp, opts.Commit); err != nil {
+		warning := fmt.Sprintf("could not update temp worktree metadata: %v", err)
+		result.Warnings = append(result.Warnings, warning)
+		r.logWarnings([]string{warning})
 	}
 	return result, nil
 }
 
 // resolveWorktree handles the fetch/check/create cycle shared by persistent
 // and temp modes. repoDir is the bare or non-bare repository, worktreePath is
-// the desired worktree location, and alwaysForce skips stale-branch recovery
-// by always using -B on worktree creation (used for temp mode).
-func (r *Resolver) resolveWorktree(ctx context.Context, repoDir string, worktreePath string, pr github.PRMetadata, alwaysForce bool) (Result, error) {
+// the desired worktree location, fetchDepth limits fetches into a shallow
+// repoDir (0 when it has full history), and alwaysForce skips stale-branch
+// recovery by always using -B on worktree creation (used for temp mode).
+func (r *Resolver) resolveWorktree(ctx context.Context, repoDir string, worktreePath string, pr github.PRMetadata, opts Options, fetchDepth int, alwaysForce bool) (Result, error) {
 	if canUseHeadRemote(pr) && isCrossRepo(pr) {
 		if err := ensureRemote(ctx, r.git, repoDir, forkRemoteName(pr), pr.HeadRepo.CloneURL); err != nil {
 			return Result{}, err
@@ -157,34 +303,44 @@ func (r *Resolver) resolveWorktree(ctx context.Context, repoDir string, worktree
 	// Keep the PR's target branch up to date for accurate local diffs.
 	var warnings []string
 	if pr.BaseRef != "" {
-		if err := r.git.FetchBranch(ctx, repoDir, "origin", pr.BaseRef); err != nil {
+		if err := fetchBase(ctx, r.git, repoDir, pr, fetchDepth); err != nil {
 			// Non-fatal: stale base is inconvenient but not blocking.
 			warnings = append(warnings, fmt.Sprintf("could not fetch base branch %s (working offline?): %v", pr.BaseRef, err))
 		}
 	}
+	project, projectWarnings := r.readProject(ctx, repoDir, pr)
+	warnings = append(warnings, projectWarnings...)
 
 	branchRef := branchRefForPR(pr)
 	if path, ok, err := r.git.HasWorktreeForBranch(ctx, repoDir, branchRef); err != nil {
 		return Result{}, err
 	} else if ok {
-		result := Result{Path: path, RepoDir: repoDir, Reused: true, Warnings: warnings}
-		target, err := fetchPR(ctx, r.git, repoDir, pr)
+		result := Result{Path: path, RepoDir: repoDir, Reused: true, Warnings: warnings, Project: project}
+		previousHeads := r.prHeadsBeforeFetch(ctx, path, pr)
+		target, err := fetchPR(ctx, r.git, repoDir, pr, fetchDepth)
 		if err != nil {
 			result.Warnings = append(result.Warnings, fmt.Sprintf("fetch failed for existing worktree (working offline?): %v", err))
+		} else {
+			result.Warnings = append(result.Warnings, r.deepenToMergeBase(ctx, repoDir, pr, target, fetchDepth)...)
+			updated, reuseWarnings, err := r.updateReusedWorktree(ctx, path, target, previousHeads[target.StartPoint], opts)
+			if err != nil {
+				return Result{}, err
+			}
+			result.Updated = updated
+			result.Warnings = append(result.Warnings, reuseWarnings...)
 		}
-		wtWarnings, err := r.ensureReadyWorktree(ctx, repoDir, path, pr, target)
-		if err != nil {
+		if err := r.ensureReadyWorktree(ctx, repoDir, path, pr, target); err != nil {
 			result.Warnings = append(result.Warnings, fmt.Sprintf("could not update worktree tracking config: %v", err))
 		}
-		result.Warnings = append(result.Warnings, wtWarnings...)
 		r.logWarnings(result.Warnings)
 		return result, nil
 	}
 
-	target, err := fetchPR(ctx, r.git, repoDir, pr)
+	target, err := fetchPR(ctx, r.git, repoDir, pr, fetchDepth)
 	if err != nil {
 		return Result{}, err
 	}
+	warnings = append(warnings, r.deepenToMergeBase(ctx, repoDir, pr, target, fetchDepth)...)
 
 	if err := os.MkdirAll(filepath.Dir(worktreePath), 0o755); err != nil {
 		return Result{}, fmt.Errorf("create worktree directory: %w", err)
@@ -212,17 +368,112 @@ func (r *Resolver) resolveWorktree(ctx context.Context, repoDir string, worktree
 		}
 	}
 
-	wtWarnings, err := r.ensureReadyWorktree(ctx, repoDir, worktreePath, pr, target)
+	if err := r.ensureReadyWorktree(ctx, repoDir, worktreePath, pr, target); err != nil {
+		return Result{}, err
+	}
+
+	result := Result{Path: worktreePath, RepoDir: repoDir, Warnings: warnings, Project: project}
+	r.logWarnings(result.Warnings)
+	return result, nil
+}
+
+// resolveCommitWorktree checks out opts.Commit next to the PR head worktree
+// at headWorktreePath, either detached or on a pr/<N>/commit-<sha> branch.
+func (r *Resolver) resolveCommitWorktree(ctx context.Context, repoDir string, headWorktreePath string, pr github.PRMetadata, opts Options, fetchDepth int) (Result, error) {
+	if canUseHeadRemote(pr) && isCrossRepo(pr) {
+		if err := ensureRemote(ctx, r.git, repoDir, forkRemoteName(pr), pr.HeadRepo.CloneURL); err != nil {
+			return Result{}, err
+		}
+	}
+
+	var warnings []string
+	sha, err := r.resolveCommit(ctx, repoDir, pr, opts.Commit, fetchDepth, &warnings)
 	if err != nil {
 		return Result{}, err
 	}
-	warnings = append(warnings, wtWarnings...)
 
-	result := Result{Path: worktreePath, RepoDir: repoDir, Warnings: warnings}
+	short := ShortSHA(sha)
+	worktreePath := headWorktreePath + "-" + short
+	branch := fmt.Sprintf("pr/%d/commit-%s", pr.Number, short)
+
+	existing, ok, err := r.findCommitWorktree(ctx, repoDir, worktreePath, branch, opts.CommitMode)
+	if err != nil {
+		return Result{}, err
+	}
+	if ok {
+		result := Result{Path: existing, RepoDir: repoDir, Reused: true, Warnings: warnings}
+		r.logWarnings(result.Warnings)
+		return result, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(worktreePath), 0o755); err != nil {
+		return Result{}, fmt.Errorf("create worktree directory: %w", err)
+	}
+	if pathExists(worktreePath) {
+		return Result{}, fmt.Errorf("worktree path already exists: %s", worktreePath)
+	}
+
+	if opts.CommitMode == CommitModeBranch {
+		// The branch name embeds the commit, so resetting a stale leftover
+		// branch with -B always points it back at the same commit.
+		if err := r.git.WorktreeAddBranch(ctx, repoDir, worktreePath, branch, sha, true); err != nil {
+			return Result{}, err
+		}
+	} else {
+		if err := r.git.WorktreeAddDetached(ctx, repoDir, worktreePath, sha); err != nil {
+			return Result{}, err
+		}
+	}
+
+	project, projectWarnings := r.readProject(ctx, repoDir, pr)
+	warnings = append(warnings, projectWarnings...)
+
+	result := Result{Path: worktreePath, RepoDir: repoDir, Warnings: warnings, Project: project}
 	r.logWarnings(result.Warnings)
 	return result, nil
 }
 
+// resolveCommit returns the full SHA for commit, fetching the PR head and,
+// for commits dropped by a force-push, the commit itself when missing.
+func (r *Resolver) resolveCommit(ctx context.Context, repoDir string, pr github.PRMetadata, commit string, fetchDepth int, warnings *[]string) (string, error) {
+	rev := commit + "^{commit}"
+	if sha, err := r.git.RevParse(ctx, repoDir, rev); err == nil && sha != "" {
+		return sha, nil
+	}
+
+	if _, err := fetchPR(ctx, r.git, repoDir, pr, fetchDepth); err != nil {
+		*warnings = append(*warnings, fmt.Sprintf("could not fetch PR head: %v", err))
+	}
+	if sha, err := r.git.RevParse(ctx, repoDir, rev); err == nil && sha != "" {
+		return sha, nil
+	}
+
+	if err := fetchRef(ctx, r.git, repoDir, "origin", commit, fetchDepth); err != nil {
+		return "", fmt.Errorf("commit %s not found in PR #%d: %w", commit, pr.Number, err)
+	}
+	sha, err := r.git.RevParse(ctx, repoDir, rev)
+	if err != nil || sha == "" {
+		return "", fmt.Errorf("commit %s not found in PR #%d", commit, pr.Number)
+	}
+	return sha, nil
+}
+
+func (r *Resolver) findCommitWorktree(ctx context.Context, repoDir string, worktreePath string, branch string, mode CommitMode) (string, bool, error) {
+	if mode == CommitModeBranch {
+		return r.git.HasWorktreeForBranch(ctx, repoDir, branch)
+	}
+	worktrees, err := r.git.WorktreeList(ctx, repoDir)
+	if err != nil {
+		return "", false, err
+	}
+	for _, wt := range worktrees {
+		if wt.Path == worktreePath && wt.Branch == "" {
+			return wt.Path, true, nil
+		}
+	}
+	return "", false, nil
+}
+
 func (r *Resolver) logWarnings(warnings []string) {
 	if r.logger == nil {
 		return
@@ -232,49 +483,57 @@ func (r *Resolver) logWarnings(warnings []string) {
 	}
 }
 
-func (r *Resolver) ensureReadyWorktree(ctx context.Context, repoDir string, worktreePath string, pr github.PRMetadata, target prCheckoutTarget) ([]string, error) {
+func (r *Resolver) ensureReadyWorktree(ctx context.Context, repoDir string, worktreePath string, pr github.PRMetadata, target prCheckoutTarget) error {
 	branchRef := branchRefForPR(pr)
 
 	if target.Upstream != "" {
 		if err := r.git.SetUpstream(ctx, worktreePath, branchRef, target.Upstream); err != nil {
-			return nil, err
+			return err
 		}
 	}
 	if isCrossRepo(pr) && !target.IsPullRef {
 		if err := r.git.ConfigSet(ctx, repoDir, "extensions.worktreeConfig", "true"); err != nil {
-			return nil, err
+			return err
 		}
 		if err := r.git.ConfigSetWorktree(ctx, worktreePath, "push.default", "upstream"); err != nil {
-			return nil, err
+			return err
 		}
 	}
 
-	// Initialize submodules in the worktree (best-effort).
-	var warnings []string
-	if err := r.git.SubmoduleUpdate(ctx, worktreePath); err != nil {
-		warnings = append(warnings, fmt.Sprintf("could not initialize submodules: %v", err))
-	}
+	return nil
+}
 
-	return warnings, nil
+// TempCleanOptions configures CleanTempWithOptions.
+type TempCleanOptions struct {
+	TTL    time.Duration
+	All    bool
+	DryRun bool
+	// States, when non-empty, removes worktrees whose PR is in one of these
+	// states (e.g. "MERGED", "CLOSED") regardless of age instead of applying
+	// TTL. PRState must be set to look states up.
+	States  []string
+	PRState PRStateFunc
 }
 
 // CleanTemp removes temp worktrees in tempDir based on ttl and options.
 func (r *Resolver) CleanTemp(ctx context.Context, tempDir string, ttl time.Duration, removeAll bool, dryRun bool) ([]CleanResult, error) {
-	entries, err := os.ReadDir(tempDir)
+	return r.CleanTempWithOptions(ctx, tempDir, TempCleanOptions{TTL: ttl, All: removeAll, DryRun: dryRun})
+}
+
+// CleanTempWithOptions removes temp worktrees in tempDir selected by opts.
+func (r *Resolver) CleanTempWithOptions(ctx context.Context, tempDir string, opts TempCleanOptions) ([]CleanResult, error) {
+	if len(opts.States) > 0 && opts.PRState == nil {
+		return nil, errors.New("state-based cleanup requires a PR state lookup")
+	}
+
+	bareDirs, err := tempBareRepoDirs(tempDir)
 	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			return nil, nil
-		}
-		return nil, fmt.Errorf("read temp dir: %w", err)
+		return nil, err
 	}
 
 	var results []CleanResult
-	for _, entry := range entries {
-		if !entry.IsDir() || !strings.HasSuffix(entry.Name(), ".git") {
-			continue
-		}
-		bareDir := filepath.Join(tempDir, entry.Name())
-		if err := r.cleanBareRepo(ctx, bareDir, ttl, removeAll, dryRun, &results); err != nil {
+	for _, bareDir := range bareDirs {
+		if err := r.cleanBareRepo(ctx, bareDir, opts, &results); err != nil {
 			return nil, err
 		}
 	}
@@ -282,7 +541,7 @@ func (r *Resolver) CleanTemp(ctx context.Context, tempDir string, ttl time.Durat
 	return results, nil
 }
 
-func (r *Resolver) cleanBareRepo(ctx context.Context, bareDir string, ttl time.Duration, removeAll bool, dryRun bool, results *[]CleanResult) error {
+func (r *Resolver) cleanBareRepo(ctx context.Context, bareDir string, opts TempCleanOptions, results *[]CleanResult) error {
 	worktrees, err := r.git.WorktreeList(ctx, bareDir)
 	if err != nil {
 		return err
@@ -301,7 +560,7 @@ func (r *Resolver) cleanBareRepo(ctx context.Context, bareDir string, ttl time.D
 		if err != nil {
 			if errors.Is(err, os.ErrNotExist) {
 				action := CleanActionPruned
-				if dryRun {
+				if opts.DryRun {
 					action = CleanActionWouldPrune
 				}
 				*results = append(*results, CleanResult{
@@ -311,8 +570,8 @@ func (r *Resolver) cleanBareRepo(ctx context.Context, bareDir string, ttl time.D
 				})
 				removed[wt.Path] = struct{}{}
 				prunedMissing = true
-				if !dryRun {
-					if err := removeTempWorktreeMarker(tempDir, wt.Path); err != nil {
+				if !opts.DryRun {
+					if err := removeWorktreeMarker(tempDir, wt.Path); err != nil {
 						return err
 					}
 				}
@@ -321,9 +580,31 @@ func (r *Resolver) cleanBareRepo(ctx context.Context, bareDir string, ttl time.D
 			return fmt.Errorf("stat worktree: %w", err)
 		}
 
-		shouldRemove := removeAll
-		if !shouldRemove {
-			lastUsed, ok, err := tempWorktreeLastUsedAt(tempDir, wt.Path)
+		shouldRemove := opts.All
+		reason := ""
+		switch {
+		case opts.All:
+		case len(opts.States) > 0:
+			info, err := r.tempWorktreeInfo(ctx, bareDir, wt)
+			if err != nil {
+				return err
+			}
+			if info.PRURL() == "" {
+				continue
+			}
+			state, err := opts.PRState(ctx, info)
+			if err != nil {
+				*results = append(*results, CleanResult{
+					Path:   wt.Path,
+					Action: CleanActionSkipped,
+					Reason: fmt.Sprintf("could not look up PR state: %v", err),
+				})
+				continue
+			}
+			reason = stateRemovalReason(state, opts.States)
+			shouldRemove = reason != ""
+		default:
+			lastUsed, ok, err := worktreeLastUsedAt(tempDir, wt.Path)
 			if err != nil {
 				return err
 			}
@@ -334,16 +615,26 @@ func (r *Resolver) cleanBareRepo(ctx context.Context, bareDir string, ttl time.D
 				}
 				lastUsed = info.ModTime()
 			}
-			shouldRemove = now.Sub(lastUsed) >= ttl
+			shouldRemove = now.Sub(lastUsed) >= opts.TTL
 		}
 
 		if !shouldRemove {
 			continue
 		}
 
-		if !removeAll {
+		if !opts.All {
 			dirty, err := r.git.IsWorktreeDirty(ctx, wt.Path)
 			if err != nil {
+				// Objects borrowed from a deleted reference repository make
+				// git status fail; leave the worktree for prt to repair.
+				if missing, _, _ := readAlternates(bareDir); len(missing) > 0 {
+					*results = append(*results, CleanResult{
+						Path:   wt.Path,
+						Action: CleanActionSkipped,
+						Reason: fmt.Sprintf("cannot check for uncommitted changes: reference repository %s is gone", strings.Join(missing, ", ")),
+					})
+					continue
+				}
 				return err
 			}
 			if dirty {
@@ -357,22 +648,22 @@ func (r *Resolver) cleanBareRepo(ctx context.Context, bareDir string, ttl time.D
 		}
 
 		action := CleanActionRemoved
-		if dryRun {
+		if opts.DryRun {
 			action = CleanActionWouldRemove
 		}
-		*results = append(*results, CleanResult{Path: wt.Path, Action: action})
+		*results = append(*results, CleanResult{Path: wt.Path, Action: action, Reason: reason})
 		removed[wt.Path] = struct{}{}
-		if !dryRun {
+		if !opts.DryRun {
 			if err := r.git.WorktreeRemove(ctx, bareDir, wt.Path, true); err != nil {
 				return err
 			}
-			if err := removeTempWorktreeMarker(tempDir, wt.Path); err != nil {
+			if err := removeWorktreeMarker(tempDir, wt.Path); err != nil {
 				return err
 			}
 		}
 	}
 
-	if prunedMissing && !dryRun {
+	if prunedMissing && !opts.DryRun {
 		if err := r.git.WorktreePrune(ctx, bareDir); err != nil {
 			return err
 		}
@@ -389,7 +680,7 @@ func (r *Resolver) cleanBareRepo(ctx context.Context, bareDir string, ttl time.D
 		remaining++
 	}
 
-	if dryRun {
+	if opts.DryRun {
 		return nil
 	}
 
@@ -433,9 +724,9 @@ func resolveRepoDir(ctx context.Context, client GitClient, projectsDir string, r
 	return primary, nil
 }
 
-func ensureRepo(ctx context.Context, client GitClient, repoDir string, cloneURL string) error {
+func ensureRepo(ctx context.Context, client GitClient, repoDir string, cloneURL string, clone git.CloneOptions) error {
 	if !pathExists(repoDir) {
-		if err := client.Clone(ctx, cloneURL, repoDir); err != nil {
+		if err := client.Clone(ctx, cloneURL, repoDir, clone); err != nil {
 			return err
 		}
 		return nil
@@ -467,9 +758,9 @@ func ensureRepo(ctx context.Context, client GitClient, repoDir string, cloneURL
 	return nil
 }
 
-func ensureBareRepo(ctx context.Context, client GitClient, bareDir string, cloneURL string) error {
+func ensureBareRepo(ctx context.Context, client GitClient, bareDir string, cloneURL string, clone git.CloneOptions) error {
 	if !pathExists(bareDir) {
-		if err := client.CloneBare(ctx, cloneURL, bareDir, 0); err != nil {
+		if err := client.CloneBare(ctx, cloneURL, bareDir, clone); err != nil {
 			return err
 		}
 	} else {
@@ -503,9 +794,9 @@ func ensureBareRepo(ctx context.Context, client GitClient, bareDir string, clone
 	return client.ConfigSet(ctx, bareDir, "remote.origin.fetch", "+refs/heads/*:refs/remotes/origin/*")
 }
 
-func fetchPR(ctx context.Context, client GitClient, repoDir string, pr github.PRMetadata) (prCheckoutTarget, error) {
+func fetchPR(ctx context.Context, client GitClient, repoDir string, pr github.PRMetadata, depth int) (prCheckoutTarget, error) {
 	target := primaryCheckoutTarget(pr)
-	err := client.Fetch(ctx, repoDir, target.Remote, target.Refspec)
+	err := fetchRef(ctx, client, repoDir, target.Remote, target.Refspec, depth)
 	if err == nil {
 		return target, nil
 	}
@@ -514,7 +805,7 @@ func fetchPR(ctx context.Context, client GitClient, repoDir string, pr github.PR
 	}
 
 	fallback := pullRefCheckoutTarget(pr)
-	if fallbackErr := client.Fetch(ctx, repoDir, fallback.Remote, fallback.Refspec); fallbackErr != nil {
+	if fallbackErr := fetchRef(ctx, client, repoDir, fallback.Remote, fallback.Refspec, depth); fallbackErr != nil {
 		return target, fmt.Errorf("direct fetch failed: %w; fallback pull ref fetch failed: %v", err, fallbackErr)
 	}
 	return fallback, nil
@@ -531,7 +822,12 @@ func worktreeName(pr github.PRMetadata) string {
 	return fmt.Sprintf("pr-%d-%s", pr.Number, sanitizeBranch(pr.HeadRef))
 }
 
+// repoSlug names alternate and temp repositories. Enterprise hosts are
+// included so identical owner/repo names on different hosts do not collide.
 func repoSlug(repo github.Repository) string {
+	if host := repo.HostName(); host != github.DefaultHost {
+		return fmt.Sprintf("%s-%s-%s", strings.ReplaceAll(host, ":", "-"), repo.Owner, repo.Name)
+	}
 	return fmt.Sprintf("%s-%s", repo.Owner, repo.Name)
 }
 
@@ -551,6 +847,11 @@ func pathExists(path string) bool {
 	return err == nil
 }
 
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
 func isCrossRepo(pr github.PRMetadata) bool {
 	return !strings.EqualFold(pr.BaseRepo.Owner, pr.HeadRepo.Owner) || !strings.EqualFold(pr.BaseRepo.Name, pr.HeadRepo.Name)
 }
@@ -584,23 +885,84 @@ func ensureRemote(ctx context.Context, client GitClient, repoDir string, name st
 }
 
 func repoMatchesOrigin(origin string, repo github.Repository) bool {
-	repoPath := strings.ToLower(fmt.Sprintf("%s/%s", repo.Owner, repo.Name))
-	return repoPathFromRemote(origin) == repoPath
+	repoPath := fmt.Sprintf("%s/%s", repo.Owner, repo.Name)
+	return strings.EqualFold(repoPathFromRemote(origin), repoPath) && hostsMatch(hostFromRemote(origin), repo.HostName())
 }
 
 func remotesMatchRepo(remoteURL string, expectedURL string) bool {
 	remoteRepo := repoPathFromRemote(remoteURL)
 	expectedRepo := repoPathFromRemote(expectedURL)
-	return remoteRepo != "" && remoteRepo == expectedRepo
+	if remoteRepo == "" || !strings.EqualFold(remoteRepo, expectedRepo) {
+		return false
+	}
+	return hostsMatch(hostFromRemote(remoteURL), hostFromRemote(expectedURL))
 }
 
-func repoPathFromRemote(remote string) string {
+// hostsMatch compares remote hosts. SSH host aliases (e.g. "github-work")
+// cannot be resolved here, so only a differing fully qualified host is
+// treated as a mismatch.
+func hostsMatch(remoteHost string, expectedHost string) bool {
+	remoteHost = stripPort(remoteHost)
+	expectedHost = stripPort(expectedHost)
+	if remoteHost == "" || expectedHost == "" || remoteHost == expectedHost {
+		return true
+	}
+	return !strings.Contains(remoteHost, ".") || !strings.Contains(expectedHost, ".")
+}
+
+func stripPort(host string) string {
+	host = strings.ToLower(host)
+	if idx := strings.LastIndex(host, ":"); idx >= 0 {
+		return host[:idx]
+	}
+	return host
+}
+
+func hostFromRemote(remote string) string {
 	remote = strings.TrimSpace(strings.ToLower(remote))
-	remote = strings.TrimSuffix(remote, ".git")
+	if strings.HasPrefix(remote, "ssh://") || strings.HasPrefix(remote, "http://") || strings.HasPrefix(remote, "https://") {
+		parsed, err := url.Parse(remote)
+		if err != nil {
+			return ""
+		}
+		return parsed.Hostname()
+	}
+	if idx := strings.Index(remote, ":"); idx >= 0 {
+		host := remote[:idx]
+		if at := strings.LastIndex(host, "@"); at >= 0 {
+			host = host[at+1:]
+		}
+		return host
+	}
+	return ""
+}
+
+// RepoFromRemote parses a git remote URL into its host, owner, and name.
+// Remotes using an SSH host alias report an empty host.
+func RepoFromRemote(remote string) (github.Repository, bool) {
+	owner, name, ok := strings.Cut(repoPathFromRemote(remote), "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return github.Repository{}, false
+	}
+	host := stripPort(hostFromRemote(remote))
+	if !strings.Contains(host, ".") {
+		host = ""
+	}
+	return github.Repository{Host: host, Owner: owner, Name: name}, true
+}
+
+// repoPathFromRemote returns the "owner/name" path of remote in its original
+// case; GitHub paths are case-insensitive, so compare with strings.EqualFold.
+func repoPathFromRemote(remote string) string {
+	remote = strings.TrimSpace(remote)
+	if len(remote) >= len(".git") && strings.EqualFold(remote[len(remote)-len(".git"):], ".git") {
+		remote = remote[:len(remote)-len(".git")]
+	}
 	if remote == "" {
 		return ""
 	}
-	if strings.HasPrefix(remote, "ssh://") || strings.HasPrefix(remote, "http://") || strings.HasPrefix(remote, "https://") {
+	scheme := strings.ToLower(remote)
+	if strings.HasPrefix(scheme, "ssh://") || strings.HasPrefix(scheme, "http://") || strings.HasPrefix(scheme, "https://") {
 		if parsed, err := url.Parse(remote); err == nil {
 			return strings.TrimPrefix(parsed.Path, "/")
 		}
@@ -664,47 +1026,3 @@ func shouldFallbackToPullRef(pr github.PRMetadata, target prCheckoutTarget) bool
 	}
 	return strings.EqualFold(pr.State, "closed") || strings.EqualFold(pr.State, "merged")
 }
-
-func tempWorktreeMarkerPath(tempDir string, worktreePath string) string {
-	sum := sha256.Sum256([]byte(worktreePath))
-	name := fmt.Sprintf("%s-%x.last-used", filepath.Base(worktreePath), sum[:8])
-	return filepath.Join(tempDir, ".prt-meta", "last-used", name)
-}
-
-func touchTempWorktreeMarker(tempDir string, worktreePath string) error {
-	now := time.Now()
-	path := tempWorktreeMarkerPath(tempDir, worktreePath)
-	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
-		return fmt.Errorf("create usage marker directory: %w", err)
-	}
-	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o644)
-	if err != nil {
-		return fmt.Errorf("open usage marker: %w", err)
-	}
-	if err := file.Close(); err != nil {
-		return fmt.Errorf("close usage marker: %w", err)
-	}
-	if err := os.Chtimes(path, now, now); err != nil {
-		return fmt.Errorf("touch usage marker: %w", err)
-	}
-	return nil
-}
-
-func tempWorktreeLastUsedAt(tempDir string, worktreePath string) (time.Time, bool, error) {
-	info, err := os.Stat(tempWorktreeMarkerPath(tempDir, worktreePath))
-	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			return time.Time{}, false, nil
-		}
-		return time.Time{}, false, fmt.Errorf("stat usage marker: %w", err)
-	}
-	return info.ModTime(), true, nil
-}
-
-func removeTempWorktreeMarker(tempDir string, worktreePath string) error {
-	err := os.Remove(tempWorktreeMarkerPath(tempDir, worktreePath))
-	if err == nil || errors.Is(err, os.ErrNotExist) {
-		return nil
-	}
-	return fmt.Errorf("remove usage marker: %w", err)
-}
